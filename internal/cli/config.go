@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pomdtr/sunbeam/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdConfig(cfg config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "config",
+		Short:   "Manage sunbeam config",
+		GroupID: CommandGroupCore,
+	}
+
+	cmd.AddCommand(NewCmdConfigShow(cfg))
+
+	return cmd
+}
+
+func NewCmdConfigShow(cfg config.Config) *cobra.Command {
+	var flags struct {
+		resolved bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show sunbeam config",
+		Long: heredoc.Doc(`
+			Show sunbeam config.
+
+			By default, the content of the config file is printed. Use --resolved to print the result of
+			the merge of the global config, the project config and their includes. The include field lists
+			every included file, and the sources field maps each extension and oneliner to the file defining it.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !flags.resolved {
+				bts, err := os.ReadFile(config.Path)
+				if err != nil {
+					return fmt.Errorf("failed to read config: %w", err)
+				}
+
+				_, err = os.Stdout.Write(bts)
+				return err
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+
+			return encoder.Encode(struct {
+				Include    []string                          `json:"include,omitempty"`
+				Oneliners  []config.Oneliner                 `json:"oneliners,omitempty"`
+				Extensions map[string]config.ExtensionConfig `json:"extensions,omitempty"`
+				Keymap     map[string]config.Keys            `json:"keymap,omitempty"`
+				Theme      *config.Theme                     `json:"theme,omitempty"`
+				Mouse      *bool                             `json:"mouse,omitempty"`
+				Sources    map[string]string                 `json:"sources"`
+			}{
+				Include:    cfg.Include,
+				Oneliners:  cfg.Oneliners,
+				Extensions: cfg.Extensions,
+				Keymap:     cfg.Keymap,
+				Theme:      cfg.Theme,
+				Mouse:      cfg.Mouse,
+				Sources:    cfg.Sources(),
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.resolved, "resolved", false, "print the merged config with the source of each entry")
+	return cmd
+}
@@ -2,7 +2,9 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -47,12 +49,7 @@ func NewCmdCustom(alias string, extension extensions.Extension, extensionConfig
 					return cmd.Usage()
 				}
 
-				history, err := history.Load(history.Path)
-				if err != nil {
-					return err
-				}
-
-				rootList := tui.NewRootList(extension.Manifest.Title, history, func() (config.Config, []sunbeam.ListItem, error) {
+				rootList := tui.NewRootList(extension.Manifest.Title, history.New(history.Path), func() (config.Config, []sunbeam.ListItem, error) {
 					cfg, err := config.Load(config.Path)
 					if err != nil {
 						return config.Config{}, nil, err
@@ -73,7 +70,7 @@ func NewCmdCustom(alias string, extension extensions.Extension, extensionConfig
 				input.Preferences = extensionConfig.Preferences
 			}
 
-			return runExtension(extension, input)
+			return runExtension(alias, extension, input)
 		},
 	}
 
@@ -156,7 +153,7 @@ func NewSubCmdCustom(alias string, extension extensions.Extension, extensionConf
 				input.Query = string(bytes.Trim(stdin, "\n"))
 			}
 
-			return runExtension(extension, input)
+			return runExtension(alias, extension, input)
 		},
 	}
 
@@ -178,28 +175,58 @@ func NewSubCmdCustom(alias string, extension extensions.Extension, extensionConf
 	return cmd
 }
 
-func runExtension(extension extensions.Extension, input sunbeam.Payload) error {
+func runExtension(alias string, extension extensions.Extension, input sunbeam.Payload) error {
 	command, ok := extension.Command(input.Command)
 	if !ok {
 		return fmt.Errorf("command %s not found", input.Command)
 	}
 
 	if !isatty.IsTerminal(os.Stdout.Fd()) {
-		cmd, err := extension.Cmd(input)
+		if command.Mode == sunbeam.CommandModeTTY {
+			cmd, err := extension.Cmd(input)
+			if err != nil {
+				return err
+			}
+
+			cmd.Stdin = os.Stdin
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+
+			if err := cmd.Run(); err != nil {
+				_ = extension.LogFailure(input, err)
+				return err
+			}
+
+			return nil
+		}
+
+		timeout := extension.Timeout(input.Command)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		cmd, err := extension.CmdContext(ctx, input)
 		if err != nil {
 			return err
 		}
+		extensions.SetProcessGroup(cmd)
 
+		var stderr bytes.Buffer
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
 
-		return cmd.Run()
+		err = cmd.Run()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = extensions.TimeoutError{Timeout: timeout}
+		}
+		_, _ = extension.WriteLog(input, stderr.Bytes(), err)
+
+		return err
 	}
 
 	switch command.Mode {
 	case sunbeam.CommandModeSearch, sunbeam.CommandModeFilter, sunbeam.CommandModeDetail:
-		runner := tui.NewRunner(extension, input)
+		runner := tui.NewRunner(alias, extension, input)
 		return tui.Draw(runner)
 	case sunbeam.CommandModeSilent:
 		return extension.Run(input)
@@ -213,7 +240,12 @@ func runExtension(extension extensions.Extension, input sunbeam.Payload) error {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 
-		return cmd.Run()
+		if err := cmd.Run(); err != nil {
+			_ = extension.LogFailure(input, err)
+			return err
+		}
+
+		return nil
 	default:
 		return fmt.Errorf("unknown command mode: %s", command.Mode)
 	}
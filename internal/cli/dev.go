@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pomdtr/sunbeam/internal/config"
+	"github.com/pomdtr/sunbeam/internal/extensions"
+	"github.com/pomdtr/sunbeam/internal/history"
+	"github.com/pomdtr/sunbeam/internal/tui"
+	"github.com/pomdtr/sunbeam/internal/utils"
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdExtensionDev(cfg config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "dev <path>",
+		Short: "Run a local extension and reload it on change",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			origin, err := normalizeOrigin(args[0])
+			if err != nil {
+				return err
+			}
+
+			if extensions.IsRemote(origin) {
+				return fmt.Errorf("dev mode only supports local extensions")
+			}
+			origin = cfg.Resolve(origin)
+
+			alias, err := extractAlias(origin)
+			if err != nil {
+				return err
+			}
+
+			// reuse the preferences of the installed extension if there is one
+			extensionConfig := config.ExtensionConfig{Origin: origin}
+			for _, installed := range cfg.Extensions {
+				if installed.ResolveOrigin() == origin {
+					extensionConfig.Preferences = installed.Preferences
+					extensionConfig.Timeout = installed.Timeout
+					break
+				}
+			}
+
+			// the preferences entered in dev mode are stored in a config of the cache directory
+			hash, err := extensions.Hash(origin)
+			if err != nil {
+				return err
+			}
+
+			// the dev config is read again on every load, so that the preferences saved during the session are used
+			devPath := filepath.Join(utils.CacheDir(), "dev", hash+".json")
+			loadDevConfig := func() (config.Config, config.ExtensionConfig, error) {
+				devConfig, err := config.LoadDev(cfg, devPath, alias, extensionConfig)
+				if err != nil {
+					return config.Config{}, config.ExtensionConfig{}, err
+				}
+
+				return devConfig, devConfig.Extensions[alias], nil
+			}
+
+			// make sure the cached manifest is up to date before starting
+			_, devExtensionConfig, err := loadDevConfig()
+			if err != nil {
+				return err
+			}
+
+			if err := extensions.Upgrade(devExtensionConfig); err != nil {
+				cmd.PrintErrf("failed to load extension: %s\n", err)
+			}
+
+			rootList := tui.NewRootList(fmt.Sprintf("%s (dev)", alias), history.New(history.Path), func() (config.Config, []sunbeam.ListItem, error) {
+				devConfig, extensionConfig, err := loadDevConfig()
+				if err != nil {
+					return config.Config{}, nil, err
+				}
+
+				extension, err := extensions.LoadExtension(extensionConfig)
+				if err != nil {
+					return config.Config{}, nil, err
+				}
+
+				return devConfig, extensionListItems(alias, extension, extensionConfig), nil
+			})
+
+			program := tui.NewProgram(rootList)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go watchExtension(ctx, origin, func() {
+				_, devExtensionConfig, err := loadDevConfig()
+				if err != nil {
+					program.Send(tui.ExtensionChangedMsg{
+						Extension: extensions.Extension{Entrypoint: origin, Config: extensionConfig},
+						Err:       err,
+					})
+					return
+				}
+
+				if err := extensions.Upgrade(devExtensionConfig); err != nil {
+					program.Send(tui.ExtensionChangedMsg{
+						Extension: extensions.Extension{Entrypoint: origin, Config: devExtensionConfig},
+						Err:       err,
+					})
+					return
+				}
+
+				extension, err := extensions.LoadExtension(devExtensionConfig)
+				program.Send(tui.ExtensionChangedMsg{Extension: extension, Err: err})
+			})
+
+			_, err = program.Run()
+			return err
+		},
+	}
+}
+
+// watchExtension polls the entrypoint and the files next to it, and calls onChange when one of them is modified.
+func watchExtension(ctx context.Context, entrypoint string, onChange func()) {
+	lastModTime := latestModTime(entrypoint)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime := latestModTime(entrypoint)
+			if !modTime.After(lastModTime) {
+				continue
+			}
+
+			lastModTime = modTime
+			onChange()
+		}
+	}
+}
+
+func latestModTime(entrypoint string) time.Time {
+	var latest time.Time
+	if info, err := os.Stat(entrypoint); err == nil {
+		latest = info.ModTime()
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(entrypoint))
+	if err != nil {
+		return latest
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") || entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest
+}
@@ -3,11 +3,13 @@ package cli
 import (
 	_ "embed"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/cli/go-gh/v2/pkg/tableprinter"
@@ -35,6 +37,10 @@ func NewCmdExtension(cfg config.Config) *cobra.Command {
 	cmd.AddCommand(NewCmdExtensionRemove(cfg))
 	cmd.AddCommand(NewCmdExtensionConfigure(cfg))
 	cmd.AddCommand(NewCmdExtensionEdit(cfg))
+	cmd.AddCommand(NewCmdExtensionLogs(cfg))
+	cmd.AddCommand(NewCmdExtensionDev(cfg))
+	cmd.AddCommand(NewCmdExtensionTest())
+	cmd.AddCommand(NewCmdExtensionLint(cfg))
 	cmd.AddCommand(NewCmdExtensionCreate())
 
 	return cmd
@@ -162,12 +168,12 @@ func NewCmdExtensionEdit(cfg config.Config) *cobra.Command {
 		Short:     "Edit a sunbeam extension",
 		Args:      cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			origin := cfg.Extensions[args[0]].Origin
-			if strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://") {
+			extensionConfig := cfg.Extensions[args[0]]
+			if extensions.IsRemote(extensionConfig.Origin) {
 				return fmt.Errorf("cannot edit remote extensions")
 			}
 
-			editCmd := exec.Command("sunbeam", "edit", cfg.Resolve(origin))
+			editCmd := exec.Command("sunbeam", "edit", extensionConfig.ResolveOrigin())
 			editCmd.Stdin = os.Stdin
 			editCmd.Stdout = os.Stdout
 			editCmd.Stderr = os.Stderr
@@ -206,7 +212,7 @@ func NewCmdExtensionInstall(cfg config.Config) *cobra.Command {
 				alias = a
 			}
 
-			if _, err := extensions.LoadExtension(origin); err != nil {
+			if _, err := extensions.LoadExtension(config.ExtensionConfig{Origin: origin}); err != nil {
 				return fmt.Errorf("failed to load extension: %w", err)
 			}
 
@@ -403,7 +409,7 @@ func NewCmdExtensionConfigure(cfg config.Config) *cobra.Command {
 				return fmt.Errorf("extension %s not found", args[0])
 			}
 
-			extension, err := extensions.LoadExtension(extensionConfig.Origin)
+			extension, err := extensions.LoadExtension(extensionConfig)
 			if err != nil {
 				return fmt.Errorf("failed to load extension: %w", err)
 			}
@@ -422,9 +428,7 @@ func NewCmdExtensionConfigure(cfg config.Config) *cobra.Command {
 			}
 
 			form := tui.NewForm(func(m map[string]any) tea.Msg {
-				extensionConfig.Preferences = m
-				cfg.Extensions[args[0]] = extensionConfig
-				if err := cfg.Save(); err != nil {
+				if err := tui.SavePreferences(cfg, args[0], extension, m); err != nil {
 					return err
 				}
 
@@ -435,3 +439,88 @@ func NewCmdExtensionConfigure(cfg config.Config) *cobra.Command {
 		},
 	}
 }
+
+func NewCmdExtensionLogs(cfg config.Config) *cobra.Command {
+	var flags struct {
+		follow bool
+	}
+
+	cmd := &cobra.Command{
+		Use:       "logs <alias>",
+		Short:     "Show the stderr logs of an extension",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: cfg.Aliases(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			extensionConfig, ok := cfg.Extensions[args[0]]
+			if !ok {
+				return fmt.Errorf("extension %s not found", args[0])
+			}
+
+			logPath, err := extensions.LogPath(extensionConfig.ResolveOrigin())
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(logPath)
+			if os.IsNotExist(err) && !flags.follow {
+				return fmt.Errorf("no logs found for extension %s", args[0])
+			} else if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to open logs: %w", err)
+			}
+
+			var offset int64
+			if f != nil {
+				n, err := io.Copy(cmd.OutOrStdout(), f)
+				f.Close()
+				if err != nil {
+					return fmt.Errorf("failed to read logs: %w", err)
+				}
+				offset = n
+			}
+
+			if !flags.follow {
+				return nil
+			}
+
+			for {
+				time.Sleep(500 * time.Millisecond)
+
+				info, err := os.Stat(logPath)
+				if os.IsNotExist(err) {
+					continue
+				} else if err != nil {
+					return err
+				}
+
+				// the log file was rotated, start from the beginning of the new one
+				if info.Size() < offset {
+					offset = 0
+				}
+
+				if info.Size() == offset {
+					continue
+				}
+
+				f, err := os.Open(logPath)
+				if err != nil {
+					return fmt.Errorf("failed to open logs: %w", err)
+				}
+
+				if _, err := f.Seek(offset, io.SeekStart); err != nil {
+					f.Close()
+					return err
+				}
+
+				n, err := io.Copy(cmd.OutOrStdout(), f)
+				f.Close()
+				if err != nil {
+					return fmt.Errorf("failed to read logs: %w", err)
+				}
+				offset += n
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&flags.follow, "follow", "f", false, "keep watching the logs for new entries")
+	return cmd
+}
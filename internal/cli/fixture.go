@@ -0,0 +1,337 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pomdtr/sunbeam/internal/config"
+	"github.com/pomdtr/sunbeam/internal/extensions"
+	"github.com/pomdtr/sunbeam/internal/schemas"
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+	"github.com/spf13/cobra"
+)
+
+type testFixture struct {
+	// the payload is kept as is, so that updating the fixture does not rewrite it
+	Payload json.RawMessage `json:"payload"`
+	// Preferences are passed to the command along with the ones of the payload
+	Preferences map[string]any  `json:"preferences,omitempty"`
+	Output      json.RawMessage `json:"output,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+func NewCmdExtensionTest() *cobra.Command {
+	var flags struct {
+		dir    string
+		update bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "test <path>",
+		Short: "Test the outputs of a local extension against fixtures",
+		Long: heredoc.Doc(`
+			Run the commands of a local extension using the payloads stored in fixture files, and compare the outputs with the expected ones.
+
+			Each fixture is a JSON file containing a payload, and either the expected output or a substring of the expected error.
+			The preferences required by the extension can be set with the preferences field:
+
+			  {
+			    "payload": { "command": "list-docsets" },
+			    "preferences": { "token": "xxx" },
+			    "output": { "items": [] }
+			  }
+
+			By default, fixtures are read from the fixtures/<name> directory next to the entrypoint.
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entrypoint, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			manifest, err := extensions.ExtractManifest(entrypoint, extensions.DefaultTimeout)
+			if err != nil {
+				return fmt.Errorf("failed to extract manifest: %w", err)
+			}
+
+			extension := extensions.Extension{
+				Manifest:   manifest,
+				Entrypoint: entrypoint,
+				Config:     config.ExtensionConfig{Origin: entrypoint},
+			}
+
+			dir := flags.dir
+			if dir == "" {
+				d, err := fixtureDir(entrypoint)
+				if err != nil {
+					return err
+				}
+				dir = d
+			}
+
+			fixturePaths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+			if err != nil {
+				return err
+			}
+
+			if len(fixturePaths) == 0 {
+				return fmt.Errorf("no fixtures found in %s", dir)
+			}
+
+			var failures int
+			for _, fixturePath := range fixturePaths {
+				name := strings.TrimSuffix(filepath.Base(fixturePath), ".json")
+				diff, err := runFixture(extension, fixturePath, flags.update)
+				if err != nil {
+					failures++
+					cmd.Printf("❌ %s: %s\n", name, err)
+					continue
+				}
+
+				if diff != "" {
+					failures++
+					cmd.Printf("❌ %s: output does not match\n%s\n", name, diff)
+					continue
+				}
+
+				if flags.update {
+					cmd.Printf("✅ %s (updated)\n", name)
+				} else {
+					cmd.Printf("✅ %s\n", name)
+				}
+			}
+
+			if failures > 0 {
+				return fmt.Errorf("%d/%d tests failed", failures, len(fixturePaths))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.dir, "dir", "", "directory containing the fixtures")
+	cmd.Flags().BoolVarP(&flags.update, "update", "u", false, "rewrite the fixtures with the actual outputs")
+	return cmd
+}
+
+func fixtureDir(entrypoint string) (string, error) {
+	name, err := extractAlias(entrypoint)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(filepath.Dir(entrypoint), "fixtures", name), nil
+}
+
+// runFixture runs the payload of a fixture, and returns a diff between the expected and the actual output.
+// If update is true, the fixture is rewritten with the actual output instead.
+func runFixture(extension extensions.Extension, fixturePath string, update bool) (string, error) {
+	fixtureBytes, err := os.ReadFile(fixturePath)
+	if err != nil {
+		return "", err
+	}
+
+	var fixture testFixture
+	if err := json.Unmarshal(fixtureBytes, &fixture); err != nil {
+		return "", fmt.Errorf("invalid fixture: %w", err)
+	}
+
+	var payload sunbeam.Payload
+	if err := json.Unmarshal(fixture.Payload, &payload); err != nil {
+		return "", fmt.Errorf("invalid payload: %w", err)
+	}
+
+	if len(fixture.Preferences) > 0 && payload.Preferences == nil {
+		payload.Preferences = make(map[string]any)
+	}
+
+	for name, value := range fixture.Preferences {
+		payload.Preferences[name] = value
+	}
+
+	command, ok := extension.Command(payload.Command)
+	if !ok {
+		return "", fmt.Errorf("command %s not found", payload.Command)
+	}
+
+	// the expected failures would fill the run logs and evict the ones of the real invocations
+	extension.DisableLogs = true
+
+	var actual []byte
+	var actualErr error
+	switch command.Mode {
+	case sunbeam.CommandModeTTY:
+		return "", fmt.Errorf("tty commands cannot be tested")
+	case sunbeam.CommandModeDetail, sunbeam.CommandModeFilter, sunbeam.CommandModeSearch:
+		output, err := extension.Output(payload)
+		if err != nil {
+			actualErr = err
+			break
+		}
+
+		if command.Mode == sunbeam.CommandModeDetail {
+			err = schemas.ValidateDetail(output)
+		} else {
+			err = schemas.ValidateList(output)
+		}
+		if err != nil {
+			actualErr = fmt.Errorf("invalid output: %w", err)
+			break
+		}
+
+		actual = output
+	case sunbeam.CommandModeSilent:
+		output, err := extension.Output(payload)
+		if err != nil {
+			actualErr = err
+			break
+		}
+
+		// silent commands do not have to output json, store the text as a string
+		actual, err = json.Marshal(string(output))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if update {
+		fixture.Output = nil
+		fixture.Error = ""
+		if actualErr != nil {
+			fixture.Error = actualErr.Error()
+		} else {
+			fixture.Output = json.RawMessage(actual)
+		}
+
+		bts, err := json.MarshalIndent(fixture, "", "  ")
+		if err != nil {
+			return "", err
+		}
+
+		return "", os.WriteFile(fixturePath, append(bts, '\n'), 0644)
+	}
+
+	if fixture.Error != "" {
+		if actualErr == nil {
+			return "", fmt.Errorf("expected error %q, got none", fixture.Error)
+		}
+
+		if !strings.Contains(actualErr.Error(), fixture.Error) {
+			return "", fmt.Errorf("expected error %q, got %q", fixture.Error, actualErr.Error())
+		}
+
+		return "", nil
+	}
+
+	if actualErr != nil {
+		return "", actualErr
+	}
+
+	expectedLines, err := normalizeJSON(fixture.Output)
+	if err != nil {
+		return "", fmt.Errorf("invalid expected output: %w", err)
+	}
+
+	actualLines, err := normalizeJSON(actual)
+	if err != nil {
+		return "", fmt.Errorf("invalid output: %w", err)
+	}
+
+	return diffLines(expectedLines, actualLines), nil
+}
+
+func normalizeJSON(bts []byte) ([]string, error) {
+	if len(bts) == 0 {
+		return nil, nil
+	}
+
+	var v any
+	if err := json.Unmarshal(bts, &v); err != nil {
+		return nil, err
+	}
+
+	normalized, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Split(string(normalized), "\n"), nil
+}
+
+// diffLines returns a unified-like diff of two slices of lines, or an empty string if they are equal.
+func diffLines(expected, actual []string) string {
+	// longest common subsequence table
+	lcs := make([][]int, len(expected)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(actual)+1)
+	}
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			if expected[i] == actual[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		prefix string
+		text   string
+	}
+
+	var lines []line
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && expected[i] == actual[j]:
+			lines = append(lines, line{" ", expected[i]})
+			i++
+			j++
+		case i < len(expected) && (j == len(actual) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{"-", expected[i]})
+			i++
+		default:
+			lines = append(lines, line{"+", actual[j]})
+			j++
+		}
+	}
+
+	// only keep the changed lines and their surrounding context
+	const contextLines = 2
+	visible := make([]bool, len(lines))
+	var changed bool
+	for idx, l := range lines {
+		if l.prefix == " " {
+			continue
+		}
+
+		changed = true
+		for k := max(0, idx-contextLines); k <= min(len(lines)-1, idx+contextLines); k++ {
+			visible[k] = true
+		}
+	}
+
+	if !changed {
+		return ""
+	}
+
+	var diff strings.Builder
+	for idx, l := range lines {
+		if !visible[idx] {
+			if idx > 0 && visible[idx-1] {
+				diff.WriteString("  ...\n")
+			}
+			continue
+		}
+
+		diff.WriteString(fmt.Sprintf("%s %s\n", l.prefix, l.text))
+	}
+
+	return diff.String()
+}
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pomdtr/sunbeam/internal/config"
+	"github.com/pomdtr/sunbeam/internal/extensions"
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+)
+
+func TestDiffLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected []string
+		actual   []string
+		diff     string
+	}{
+		{
+			name:     "equal",
+			expected: []string{"a", "b"},
+			actual:   []string{"a", "b"},
+			diff:     "",
+		},
+		{
+			name:     "changed line",
+			expected: []string{"a", "b", "c"},
+			actual:   []string{"a", "x", "c"},
+			diff:     "  a\n- b\n+ x\n  c\n",
+		},
+		{
+			name:     "added line",
+			expected: []string{"a"},
+			actual:   []string{"a", "b"},
+			diff:     "  a\n+ b\n",
+		},
+		{
+			name:     "removed line",
+			expected: []string{"a", "b"},
+			actual:   []string{"b"},
+			diff:     "- a\n  b\n",
+		},
+		{
+			name:     "distant changes",
+			expected: []string{"a", "1", "2", "3", "4", "5", "6", "b"},
+			actual:   []string{"x", "1", "2", "3", "4", "5", "6", "y"},
+			diff:     "- a\n+ x\n  1\n  2\n  ...\n  5\n  6\n- b\n+ y\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := diffLines(tc.expected, tc.actual); diff != tc.diff {
+				t.Fatalf("expected diff:\n%s\ngot:\n%s", tc.diff, diff)
+			}
+		})
+	}
+}
+
+// fixtureExtension returns an extension whose show command requires a token preference.
+func fixtureExtension(t *testing.T) extensions.Extension {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test extension is a shell script")
+	}
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	entrypoint := filepath.Join(t.TempDir(), "fixture.sh")
+	script := `#!/bin/sh
+case "$1" in
+*'"token":"secret"'*) echo '{"text": "hello"}' ;;
+*) echo "invalid token" >&2; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(entrypoint, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	return extensions.Extension{
+		Manifest: sunbeam.Manifest{
+			Title:       "Fixture",
+			Preferences: []sunbeam.Input{{Name: "token", Title: "Token", Type: sunbeam.InputString}},
+			Commands:    []sunbeam.CommandSpec{{Name: "show", Title: "Show", Mode: sunbeam.CommandModeDetail}},
+		},
+		Entrypoint: entrypoint,
+		Config:     config.ExtensionConfig{Origin: entrypoint},
+	}
+}
+
+func writeFixture(t *testing.T, content string) string {
+	t.Helper()
+	fixturePath := filepath.Join(t.TempDir(), "show.json")
+	if err := os.WriteFile(fixturePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return fixturePath
+}
+
+func TestRunFixture(t *testing.T) {
+	extension := fixtureExtension(t)
+
+	testCases := []struct {
+		name    string
+		fixture string
+		diff    bool
+		err     string
+	}{
+		{
+			name:    "matching output",
+			fixture: `{"payload": {"command": "show"}, "preferences": {"token": "secret"}, "output": {"text": "hello"}}`,
+		},
+		{
+			name:    "different output",
+			fixture: `{"payload": {"command": "show"}, "preferences": {"token": "secret"}, "output": {"text": "bye"}}`,
+			diff:    true,
+		},
+		{
+			name:    "expected error",
+			fixture: `{"payload": {"command": "show"}, "preferences": {"token": "wrong"}, "error": "invalid token"}`,
+		},
+		{
+			name:    "missing preference",
+			fixture: `{"payload": {"command": "show"}, "output": {"text": "hello"}}`,
+			err:     "missing required preference token",
+		},
+		{
+			name:    "unknown command",
+			fixture: `{"payload": {"command": "hide"}, "output": {}}`,
+			err:     "command hide not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := runFixture(extension, writeFixture(t, tc.fixture), false)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.diff != (diff != "") {
+				t.Fatalf("unexpected diff: %q", diff)
+			}
+		})
+	}
+}
+
+func TestRunFixtureDisablesLogs(t *testing.T) {
+	extension := fixtureExtension(t)
+	if _, err := runFixture(extension, writeFixture(t, `{"payload": {"command": "show"}, "preferences": {"token": "wrong"}, "error": "invalid token"}`), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	logPath, err := extensions.LogPath(extension.Config.ResolveOrigin())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Dir(logPath)); !os.IsNotExist(err) {
+		t.Fatalf("expected the fixture runs not to be logged, got %v", err)
+	}
+}
+
+func TestRunFixtureUpdate(t *testing.T) {
+	extension := fixtureExtension(t)
+	fixturePath := writeFixture(t, `{"payload": {"command": "show"}, "preferences": {"token": "secret"}, "error": "outdated"}`)
+
+	if _, err := runFixture(extension, fixturePath, true); err != nil {
+		t.Fatalf("failed to update fixture: %v", err)
+	}
+
+	content, err := os.ReadFile(fixturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fixture testFixture
+	if err := json.Unmarshal(content, &fixture); err != nil {
+		t.Fatal(err)
+	}
+
+	var output map[string]any
+	if err := json.Unmarshal(fixture.Output, &output); err != nil || fixture.Error != "" || output["text"] != "hello" {
+		t.Fatalf("expected the fixture to contain the actual output, got %s", content)
+	}
+
+	if fixture.Preferences["token"] != "secret" {
+		t.Fatalf("expected the preferences to be kept, got %s", content)
+	}
+
+	if diff, err := runFixture(extension, fixturePath, false); err != nil || diff != "" {
+		t.Fatalf("expected the updated fixture to pass, got diff %q and error %v", diff, err)
+	}
+}
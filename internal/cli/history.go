@@ -0,0 +1,319 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/cli/go-gh/v2/pkg/text"
+	"github.com/mattn/go-isatty"
+	"github.com/pomdtr/sunbeam/internal/config"
+	"github.com/pomdtr/sunbeam/internal/history"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func NewCmdHistory() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "history",
+		Short:   "Manage sunbeam history",
+		GroupID: CommandGroupCore,
+	}
+
+	cmd.AddCommand(NewCmdHistoryList())
+	cmd.AddCommand(NewCmdHistoryRemove())
+	cmd.AddCommand(NewCmdHistoryPrune())
+	cmd.AddCommand(NewCmdHistoryExport())
+	cmd.AddCommand(NewCmdHistoryImport())
+
+	return cmd
+}
+
+func NewCmdHistoryList() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "List history entries",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := history.Load(history.Path)
+			if err != nil {
+				return fmt.Errorf("failed to load history: %w", err)
+			}
+
+			entries := h.Entries()
+			keys := make([]string, 0, len(entries))
+			for key := range entries {
+				keys = append(keys, key)
+			}
+			sort.Slice(keys, func(i, j int) bool {
+				return entries[keys[i]].LastUsed > entries[keys[j]].LastUsed
+			})
+
+			var t tableprinter.TablePrinter
+			isTTY := isatty.IsTerminal(os.Stdout.Fd())
+			if isTTY {
+				w, _, err := term.GetSize(int(os.Stdout.Fd()))
+				if err != nil {
+					return err
+				}
+				t = tableprinter.New(os.Stdout, true, w)
+			} else {
+				t = tableprinter.New(os.Stdout, false, 0)
+			}
+
+			now := time.Now()
+			for _, key := range keys {
+				entry := entries[key]
+				lastUsed := time.Unix(entry.LastUsed, 0)
+
+				t.AddField(key)
+				t.AddField(strconv.Itoa(entry.Count))
+				if isTTY {
+					t.AddField(text.RelativeTimeAgo(now, lastUsed))
+				} else {
+					t.AddField(lastUsed.Format(time.RFC3339))
+				}
+				t.EndRow()
+			}
+
+			return t.Render()
+		},
+	}
+}
+
+func NewCmdHistoryRemove() *cobra.Command {
+	var flags struct {
+		prefix bool
+	}
+
+	cmd := &cobra.Command{
+		Use:     "remove <id>",
+		Short:   "Remove history entries",
+		Aliases: []string{"rm"},
+		Args:    cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			h, err := history.Load(history.Path)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+
+			var completions []string
+			for key := range h.Entries() {
+				completions = append(completions, key)
+			}
+
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := history.Load(history.Path)
+			if err != nil {
+				return fmt.Errorf("failed to load history: %w", err)
+			}
+
+			var removed int
+			for key := range h.Entries() {
+				for _, arg := range args {
+					if key == arg || (flags.prefix && strings.HasPrefix(key, arg)) {
+						h.Remove(key)
+						removed++
+						break
+					}
+				}
+			}
+
+			if removed == 0 {
+				return fmt.Errorf("no matching history entry")
+			}
+
+			if err := h.Save(); err != nil {
+				return fmt.Errorf("failed to save history: %w", err)
+			}
+
+			cmd.Printf("✅ Removed %d entries\n", removed)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.prefix, "prefix", false, "remove all entries starting with the given ids")
+	return cmd
+}
+
+func NewCmdHistoryPrune() *cobra.Command {
+	var flags struct {
+		uninstalled bool
+		olderThan   string
+	}
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Prune history entries",
+		Long: heredoc.Doc(`
+			Prune history entries.
+
+			Entries belonging to uninstalled extensions or removed oneliners can be pruned with --uninstalled.
+			The history is shared by all the directories, so the entries are checked against the global config and its includes.
+			Entries not used for a given duration can be pruned with --older-than (ex: 12h, 30d).
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !flags.uninstalled && flags.olderThan == "" {
+				return fmt.Errorf("either --uninstalled or --older-than must be set")
+			}
+
+			var maxAge time.Duration
+			if flags.olderThan != "" {
+				d, err := parseDuration(flags.olderThan)
+				if err != nil {
+					return fmt.Errorf("invalid duration: %w", err)
+				}
+				maxAge = d
+			}
+
+			// the config of the current directory would prune the entries of the other projects
+			var globalConfig config.Config
+			if _, err := os.Stat(config.GlobalPath); flags.uninstalled && err == nil {
+				globalConfig, err = config.Load(config.GlobalPath)
+				if err != nil {
+					return fmt.Errorf("failed to load global config: %w", err)
+				}
+			}
+
+			h, err := history.Load(history.Path)
+			if err != nil {
+				return fmt.Errorf("failed to load history: %w", err)
+			}
+
+			now := time.Now()
+			var removed int
+			for key, entry := range h.Entries() {
+				if flags.uninstalled && !isInstalled(globalConfig, key) {
+					h.Remove(key)
+					removed++
+					continue
+				}
+
+				if maxAge > 0 && now.Sub(time.Unix(entry.LastUsed, 0)) > maxAge {
+					h.Remove(key)
+					removed++
+				}
+			}
+
+			if err := h.Save(); err != nil {
+				return fmt.Errorf("failed to save history: %w", err)
+			}
+
+			cmd.Printf("✅ Pruned %d entries\n", removed)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.uninstalled, "uninstalled", false, "prune entries of uninstalled extensions")
+	cmd.Flags().StringVar(&flags.olderThan, "older-than", "", "prune entries not used since the given duration")
+	return cmd
+}
+
+// isInstalled checks if the extension or oneliner referenced by a history key is still in the config.
+func isInstalled(cfg config.Config, key string) bool {
+	key = strings.TrimPrefix(key, paletteHistoryPrefix+" - ")
+	alias, rest, _ := strings.Cut(key, " - ")
+	if alias == "oneliner" {
+		for _, oneliner := range cfg.Oneliners {
+			if oneliner.Title == rest {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	_, ok := cfg.Extensions[alias]
+	return ok
+}
+
+// parseDuration extends time.ParseDuration with a day unit.
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days: %s", days)
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+func NewCmdHistoryExport() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export",
+		Short: "Export history as json",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := history.Load(history.Path)
+			if err != nil {
+				return fmt.Errorf("failed to load history: %w", err)
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+
+			return encoder.Encode(h)
+		},
+	}
+}
+
+func NewCmdHistoryImport() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import [file]",
+		Short: "Import history from json",
+		Long: heredoc.Doc(`
+			Import history from json.
+
+			The imported entries are merged with the existing ones, keeping the largest count and the latest use of each entry. If no file is provided, the history is read from stdin.
+		`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var bts []byte
+			var err error
+			if len(args) == 0 || args[0] == "-" {
+				if isatty.IsTerminal(os.Stdin.Fd()) {
+					return fmt.Errorf("no input provided")
+				}
+				bts, err = io.ReadAll(os.Stdin)
+			} else {
+				bts, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read history: %w", err)
+			}
+
+			entries, err := history.Decode(bts)
+			if err != nil {
+				return fmt.Errorf("failed to parse history: %w", err)
+			}
+
+			h, err := history.Load(history.Path)
+			if err != nil {
+				return fmt.Errorf("failed to load history: %w", err)
+			}
+
+			h.Merge(entries)
+			if err := h.Save(); err != nil {
+				return fmt.Errorf("failed to save history: %w", err)
+			}
+
+			cmd.Printf("✅ Imported %d entries\n", len(entries))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pomdtr/sunbeam/internal/config"
+)
+
+func TestIsInstalled(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sunbeam.json":  `{"include": ["included.json"], "extensions": {"devdocs": {"origin": "./devdocs.sh"}}, "oneliners": [{"title": "Build", "command": "make"}]}`,
+		"included.json": `{"extensions": {"github": {"origin": "./github.sh"}}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the test config takes the place of the global config, which would be layered below it otherwise
+	globalPath := config.GlobalPath
+	config.GlobalPath = filepath.Join(dir, "sunbeam.json")
+	t.Cleanup(func() { config.GlobalPath = globalPath })
+
+	cfg, err := config.Load(config.GlobalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		key       string
+		installed bool
+	}{
+		{key: "devdocs - search - a", installed: true},
+		{key: "github - list-repos - b", installed: true},
+		{key: "project - list - c", installed: false},
+		{key: "palette - devdocs - search", installed: true},
+		{key: "palette - project - list", installed: false},
+		{key: "oneliner - Build", installed: true},
+		{key: "oneliner - Deploy", installed: false},
+	}
+
+	for _, tc := range testCases {
+		if installed := isInstalled(cfg, tc.key); installed != tc.installed {
+			t.Errorf("isInstalled(%q): expected %v, got %v", tc.key, tc.installed, installed)
+		}
+	}
+}
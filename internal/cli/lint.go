@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pomdtr/sunbeam/internal/config"
+	"github.com/pomdtr/sunbeam/internal/extensions"
+	"github.com/pomdtr/sunbeam/internal/schemas"
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdExtensionLint(cfg config.Config) *cobra.Command {
+	var flags struct {
+		dir string
+	}
+
+	cmd := &cobra.Command{
+		Use:   "lint <path>",
+		Short: "Look for semantic issues in a local extension",
+		Long: heredoc.Doc(`
+			Check the manifest of a local extension for issues that the schema cannot catch, such as duplicate command names or unknown commands referenced by run actions.
+
+			The expected outputs of the fixtures used by the test command are checked too.
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entrypoint, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			manifest, err := extensions.ExtractManifest(entrypoint, extensions.DefaultTimeout)
+			if err != nil {
+				return fmt.Errorf("failed to extract manifest: %w", err)
+			}
+
+			dir := flags.dir
+			if dir == "" {
+				d, err := fixtureDir(entrypoint)
+				if err != nil {
+					return err
+				}
+				dir = d
+			}
+
+			fixturePaths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+			if err != nil {
+				return err
+			}
+
+			var warnings []extensions.Warning
+			references := make(map[string]bool)
+			for _, fixturePath := range fixturePaths {
+				fixtureWarnings, actions, err := lintFixture(manifest, fixturePath)
+				if err != nil {
+					return fmt.Errorf("failed to lint %s: %w", fixturePath, err)
+				}
+
+				warnings = append(warnings, fixtureWarnings...)
+				for name := range extensions.RunReferences(actions) {
+					references[name] = true
+				}
+			}
+
+			extension := extensions.Extension{Manifest: manifest}
+			for alias, extensionConfig := range cfg.Extensions {
+				if extensionConfig.ResolveOrigin() != entrypoint {
+					continue
+				}
+
+				for i, rootItem := range extensionConfig.Root {
+					references[rootItem.Command] = true
+					if _, ok := extension.Command(rootItem.Command); !ok {
+						warnings = append(warnings, extensions.Warning{
+							Location: fmt.Sprintf("%s#/extensions/%s/root/%d/command", extensionConfig.Source(), alias, i),
+							Message:  fmt.Sprintf("root item points to unknown command %s", rootItem.Command),
+						})
+					}
+				}
+			}
+
+			for _, warning := range extensions.LintManifest(manifest, references) {
+				warning.Location = fmt.Sprintf("manifest#%s", warning.Location)
+				warnings = append(warnings, warning)
+			}
+
+			if len(warnings) == 0 {
+				cmd.Println("✅ No issues found")
+				return nil
+			}
+
+			for _, warning := range warnings {
+				cmd.Printf("⚠️  %s\n", warning)
+			}
+
+			return fmt.Errorf("found %d issues", len(warnings))
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.dir, "dir", "", "directory containing the fixtures")
+	return cmd
+}
+
+// lintFixture checks the expected output of a fixture, and returns the actions it contains.
+func lintFixture(manifest sunbeam.Manifest, fixturePath string) ([]extensions.Warning, []sunbeam.Action, error) {
+	fixtureBytes, err := os.ReadFile(fixturePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fixture testFixture
+	if err := json.Unmarshal(fixtureBytes, &fixture); err != nil {
+		return nil, nil, err
+	}
+
+	var payload sunbeam.Payload
+	if err := json.Unmarshal(fixture.Payload, &payload); err != nil {
+		return nil, nil, err
+	}
+
+	extension := extensions.Extension{Manifest: manifest}
+	location := fmt.Sprintf("%s#/output", fixturePath)
+	command, ok := extension.Command(payload.Command)
+	if !ok {
+		return []extensions.Warning{{
+			Location: fmt.Sprintf("%s#/payload/command", fixturePath),
+			Message:  fmt.Sprintf("unknown command %s", payload.Command),
+		}}, nil, nil
+	}
+
+	if len(fixture.Output) == 0 {
+		return nil, nil, nil
+	}
+
+	switch command.Mode {
+	case sunbeam.CommandModeFilter, sunbeam.CommandModeSearch:
+		if err := schemas.ValidateList(fixture.Output); err != nil {
+			return []extensions.Warning{{Location: location, Message: err.Error()}}, nil, nil
+		}
+
+		var list sunbeam.List
+		if err := json.Unmarshal(fixture.Output, &list); err != nil {
+			return nil, nil, err
+		}
+
+		return extensions.LintList(manifest, list, location), extensions.ListActions(list), nil
+	case sunbeam.CommandModeDetail:
+		if err := schemas.ValidateDetail(fixture.Output); err != nil {
+			return []extensions.Warning{{Location: location, Message: err.Error()}}, nil, nil
+		}
+
+		var detail sunbeam.Detail
+		if err := json.Unmarshal(fixture.Output, &detail); err != nil {
+			return nil, nil, err
+		}
+
+		return extensions.LintDetail(manifest, detail, location), detail.Actions, nil
+	default:
+		return nil, nil, nil
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/pomdtr/sunbeam/internal/config"
 	"github.com/pomdtr/sunbeam/internal/extensions"
 	"github.com/pomdtr/sunbeam/internal/history"
+	"github.com/pomdtr/sunbeam/internal/session"
 	"github.com/pomdtr/sunbeam/internal/tui"
 	"github.com/pomdtr/sunbeam/internal/utils"
 	"github.com/pomdtr/sunbeam/pkg/sunbeam"
@@ -137,11 +138,31 @@ See https://pomdtr.github.io/sunbeam for more information.`,
 	if err != nil {
 		return nil, err
 	}
+
+	// an invalid keymap should not prevent the other commands from running
+	keymap, err := tui.NewKeymap(cfg.Keymap)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid keymap, using the default keys: %s\n", err)
+		keymap = tui.DefaultKeymap()
+	}
+	tui.SetKeymap(keymap)
+
+	theme, err := tui.NewTheme(cfg.Theme)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid theme, using the default one: %s\n", err)
+		// the default theme is always valid
+		theme, _ = tui.NewTheme(nil)
+	}
+	tui.SetTheme(theme)
+	tui.EnableMouse(cfg.Mouse != nil && *cfg.Mouse)
+
 	rootCmd.AddCommand(NewCmdExtension(cfg))
+	rootCmd.AddCommand(NewCmdHistory())
+	rootCmd.AddCommand(NewCmdConfig(cfg))
 
 	extensionMap := make(map[string]extensions.Extension)
 	for alias, extensionConfig := range cfg.Extensions {
-		extension, err := extensions.LoadExtension(extensionConfig.Origin)
+		extension, err := extensions.LoadExtension(extensionConfig)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error loading extension %s: %s\n", alias, err)
 			continue
@@ -155,47 +176,80 @@ See https://pomdtr.github.io/sunbeam for more information.`,
 		rootCmd.AddCommand(command)
 	}
 
+	rootCmd.Flags().Bool("resume", false, "Restore the pages open when sunbeam last exited")
+	rootCmd.Flags().Bool("palette", false, "List the commands of every extension")
 	rootCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		if !isatty.IsTerminal(os.Stdout.Fd()) {
 			encoder := json.NewEncoder(os.Stdout)
 			encoder.SetIndent("", "  ")
 			encoder.SetEscapeHTML(false)
 
-			return encoder.Encode(cfg)
+			return encoder.Encode(redactSecrets(cfg, extensionMap))
 		}
-		history, err := history.Load(history.Path)
-		if err != nil {
-			return err
+		title, alternateTitle := "Sunbeam", "Command Palette"
+		palette, _ := cmd.Flags().GetBool("palette")
+		if palette {
+			title, alternateTitle = alternateTitle, title
 		}
 
-		rootList := tui.NewRootList("Sunbeam", history, func() (config.Config, []sunbeam.ListItem, error) {
-			cfg, err := config.Load(config.Path)
+		rootList := tui.NewRootList(title, history.New(history.Path), rootListGenerator(palette))
+		// the root list can be switched to the command palette and back from the tui
+		rootList.SetAlternate(alternateTitle, rootListGenerator(!palette))
+
+		var stacked []tui.Page
+		if resume, _ := cmd.Flags().GetBool("resume"); resume {
+			s, err := session.Load(session.Path)
 			if err != nil {
-				return config.Config{}, nil, err
+				return err
 			}
 
-			var items []sunbeam.ListItem
-			items = append(items, onelinerListItems(cfg.Oneliners)...)
+			stacked = tui.RestoreSession(cfg, s)
+		}
 
-			extensionMap := make(map[string]extensions.Extension)
-			for alias, extensionConfig := range cfg.Extensions {
-				extension, err := extensions.LoadExtension(extensionConfig.Origin)
-				if err != nil {
-					continue
-				}
-				extensionMap[alias] = extension
-				items = append(items, extensionListItems(alias, extension, extensionConfig)...)
-			}
+		pages, err := tui.Run(rootList, stacked...)
+		if err != nil {
+			return err
+		}
 
-			return cfg, items, nil
-		})
-		return tui.Draw(rootList)
+		// the session is only saved by the root invocation, --resume always starts from the root list
+		s, ok := tui.NewSession(pages)
+		if !ok {
+			return nil
+		}
 
+		return s.Save(session.Path)
 	}
 
 	return rootCmd, nil
 }
 
+// redactSecrets returns a copy of the config without the preferences marked as secret by the extensions.
+func redactSecrets(cfg config.Config, extensionMap map[string]extensions.Extension) config.Config {
+	redacted := make(map[string]config.ExtensionConfig, len(cfg.Extensions))
+	for alias, extensionConfig := range cfg.Extensions {
+		preferences := make(map[string]any, len(extensionConfig.Preferences))
+		for name, value := range extensionConfig.Preferences {
+			preferences[name] = value
+		}
+
+		for _, input := range extensionMap[alias].Manifest.Preferences {
+			if input.Secret {
+				delete(preferences, input.Name)
+			}
+		}
+
+		if len(preferences) == 0 {
+			preferences = nil
+		}
+
+		extensionConfig.Preferences = preferences
+		redacted[alias] = extensionConfig
+	}
+
+	cfg.Extensions = redacted
+	return cfg
+}
+
 func buildDoc(command *cobra.Command) (string, error) {
 	var page strings.Builder
 	err := doc.GenMarkdown(command, &page)
@@ -266,6 +320,36 @@ func onelinerListItems(oneliners []config.Oneliner) []sunbeam.ListItem {
 	return items
 }
 
+// rootListGenerator returns the items of the root list, or the ones of the command palette.
+func rootListGenerator(palette bool) func() (config.Config, []sunbeam.ListItem, error) {
+	return func() (config.Config, []sunbeam.ListItem, error) {
+		cfg, err := config.Load(config.Path)
+		if err != nil {
+			return config.Config{}, nil, err
+		}
+
+		var items []sunbeam.ListItem
+		if !palette {
+			items = append(items, onelinerListItems(cfg.Oneliners)...)
+		}
+
+		for alias, extensionConfig := range cfg.Extensions {
+			extension, err := extensions.LoadExtension(extensionConfig)
+			if err != nil {
+				continue
+			}
+
+			if palette {
+				items = append(items, paletteListItems(alias, extension)...)
+			} else {
+				items = append(items, extensionListItems(alias, extension, extensionConfig)...)
+			}
+		}
+
+		return cfg, items, nil
+	}
+}
+
 func extensionListItems(alias string, extension extensions.Extension, extensionConfig config.ExtensionConfig) []sunbeam.ListItem {
 	var items []sunbeam.ListItem
 
@@ -333,3 +417,50 @@ func extensionListItems(alias string, extension extensions.Extension, extensionC
 
 	return items
 }
+
+// paletteHistoryPrefix starts the history keys of the command palette items.
+const paletteHistoryPrefix = "palette"
+
+// paletteListItems returns every command of the extension. Hidden commands usually expect
+// params from the page opening them, so they are only listed when they don't require any.
+// The params of the other commands are asked with a form.
+func paletteListItems(alias string, extension extensions.Extension) []sunbeam.ListItem {
+	var items []sunbeam.ListItem
+	for _, command := range extension.Manifest.Commands {
+		if command.Hidden && hasRequiredParams(command) {
+			continue
+		}
+
+		subtitle := alias
+		if command.Description != "" {
+			subtitle = fmt.Sprintf("%s · %s", alias, command.Description)
+		}
+
+		items = append(items, sunbeam.ListItem{
+			// the palette has its own history, the items of the root list have the same commands
+			Id:          fmt.Sprintf("%s - %s - %s", paletteHistoryPrefix, alias, command.Name),
+			Title:       command.Title,
+			Subtitle:    subtitle,
+			Accessories: []string{extension.Manifest.Title},
+			Actions: []sunbeam.Action{
+				{
+					Title: "Run",
+					Type:  sunbeam.ActionTypeRun,
+					Run:   &sunbeam.RunAction{Extension: alias, Command: command.Name},
+				},
+			},
+		})
+	}
+
+	return items
+}
+
+func hasRequiredParams(command sunbeam.CommandSpec) bool {
+	for _, param := range command.Params {
+		if !param.Optional {
+			return true
+		}
+	}
+
+	return false
+}
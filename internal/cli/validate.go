@@ -117,12 +117,15 @@ func NewCmdValidateConfig() *cobra.Command {
 		Short: "Validate a config",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// stdin is parsed as jsonc
+			configPath := "sunbeam.jsonc"
 			var inputBytes []byte
 			if len(args) > 0 {
 				b, err := os.ReadFile(args[0])
 				if err != nil {
 					return err
 				}
+				configPath = args[0]
 				inputBytes = b
 			} else if !isatty.IsTerminal(os.Stdin.Fd()) {
 				b, err := io.ReadAll(os.Stdin)
@@ -135,9 +138,15 @@ func NewCmdValidateConfig() *cobra.Command {
 				if err != nil {
 					return err
 				}
+				configPath = config.Path
 				inputBytes = b
 			}
 
+			inputBytes, err := config.ToJSON(configPath, inputBytes)
+			if err != nil {
+				return fmt.Errorf("config is invalid: %s", err)
+			}
+
 			if err := schemas.ValidateConfig(inputBytes); err != nil {
 				return fmt.Errorf("config is invalid: %s", err)
 			}
@@ -1,53 +1,91 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/pomdtr/sunbeam/internal/schemas"
 	"github.com/pomdtr/sunbeam/internal/utils"
 )
 
-var Path string
+// Path is the config file modified by sunbeam, GlobalPath is the config file
+// used as the base layer when a project config is found.
+var (
+	Path       string
+	GlobalPath string
+)
 
 func init() {
 	if env, ok := os.LookupEnv("SUNBEAM_CONFIG"); ok {
 		Path = env
+		GlobalPath = env
 		return
 	}
 
+	GlobalPath = filepath.Join(utils.ConfigDir(), "sunbeam.json")
+	if configPath, ok := findConfig(utils.ConfigDir()); ok {
+		GlobalPath = configPath
+	}
+
 	currentDir, err := os.Getwd()
 	if err != nil {
 		panic(err)
 	}
 
 	for currentDir != "/" {
-		if _, err := os.Stat(filepath.Join(currentDir, "sunbeam.json")); err == nil {
-			Path = filepath.Join(currentDir, "sunbeam.json")
+		if configPath, ok := findConfig(currentDir); ok {
+			Path = configPath
 			return
 		}
 		currentDir = filepath.Dir(currentDir)
 	}
 
-	Path = filepath.Join(utils.ConfigDir(), "sunbeam.json")
+	Path = GlobalPath
+}
+
+// findConfig returns the config file of a directory, if any.
+func findConfig(dir string) (string, bool) {
+	for _, name := range configNames {
+		configPath := filepath.Join(dir, name)
+		if _, err := os.Stat(configPath); err == nil {
+			return configPath, true
+		}
+	}
+
+	return "", false
 }
 
 type Config struct {
+	Include    []string                   `json:"include,omitempty"`
 	Oneliners  []Oneliner                 `json:"oneliners,omitempty"`
 	Extensions map[string]ExtensionConfig `json:"extensions,omitempty"`
+	Secrets    *SecretStore               `json:"secrets,omitempty"`
+	Keymap     map[string]Keys            `json:"keymap,omitempty"`
+	Theme      *Theme                     `json:"theme,omitempty"`
+	Mouse      *bool                      `json:"mouse,omitempty"`
 	path       string                     `json:"-"`
+	// file defining each entry when the config was loaded, indexed by extension alias or oneliner title
+	sources map[string]string `json:"-"`
+	// content of the config when it was loaded, used to find the changes to save
+	base []byte `json:"-"`
 }
 
 func (cfg Config) Resolve(path string) string {
+	return resolvePath(filepath.Dir(cfg.path), path)
+}
+
+func resolvePath(dir string, path string) string {
 	if strings.HasPrefix(path, "~/") {
 		return filepath.Join(os.Getenv("HOME"), path[2:])
 	}
 
 	if !filepath.IsAbs(path) {
-		return filepath.Join(filepath.Dir(cfg.path), path)
+		return filepath.Join(dir, path)
 	}
 
 	return path
@@ -57,6 +95,31 @@ type ExtensionConfig struct {
 	Origin      string         `json:"origin,omitempty"`
 	Preferences map[string]any `json:"preferences,omitempty"`
 	Root        []RootItem     `json:"root,omitempty"`
+	Timeout     int            `json:"timeout,omitempty"`
+	// file defining the extension, relative origins are resolved from its directory
+	source string `json:"-"`
+	// store used to resolve the secret references of the preferences
+	secrets SecretStore `json:"-"`
+}
+
+// Source returns the config file defining the extension.
+func (e ExtensionConfig) Source() string {
+	if e.source == "" {
+		return Path
+	}
+
+	return e.source
+}
+
+// ResolveOrigin returns the origin of a local extension as an absolute path, remote origins are returned as is.
+// It identifies the extension, two configs declaring the same relative origin from different directories
+// refer to different extensions.
+func (e ExtensionConfig) ResolveOrigin() string {
+	if strings.HasPrefix(e.Origin, "http://") || strings.HasPrefix(e.Origin, "https://") {
+		return e.Origin
+	}
+
+	return resolvePath(filepath.Dir(e.Source()), e.Origin)
 }
 
 type RootItem struct {
@@ -73,6 +136,15 @@ type Oneliner struct {
 	Exit        bool   `json:"exit,omitempty"`
 }
 
+// SecretStore returns the store of the secret preferences.
+func (cfg Config) SecretStore() SecretStore {
+	if cfg.Secrets == nil {
+		return SecretStore{}
+	}
+
+	return *cfg.Secrets
+}
+
 func (cfg Config) Aliases() []string {
 	var aliases []string
 	for alias := range cfg.Extensions {
@@ -82,42 +154,338 @@ func (cfg Config) Aliases() []string {
 	return aliases
 }
 
+// Sources returns the file defining each extension and oneliner of the config,
+// indexed by the json pointer of the entry.
+func (cfg Config) Sources() map[string]string {
+	sources := make(map[string]string)
+	for alias, extension := range cfg.Extensions {
+		sources[fmt.Sprintf("/extensions/%s", alias)] = extension.Source()
+	}
+
+	for i, oneliner := range cfg.Oneliners {
+		sources[fmt.Sprintf("/oneliners/%d", i)] = cfg.source(onelinerKey(oneliner.Title))
+	}
+
+	return sources
+}
+
+func extensionKey(alias string) string {
+	return "extensions/" + alias
+}
+
+func onelinerKey(title string) string {
+	return "oneliners/" + title
+}
+
+// source returns the file where an entry is saved: the file defining it, or the config file for new entries.
+func (cfg Config) source(key string) string {
+	if source, ok := cfg.sources[key]; ok {
+		return source
+	}
+
+	return cfg.path
+}
+
+// Load reads the config file and the files it includes. If the config file is a project config,
+// it is layered on top of the global config. Entries of the upper layers override the ones
+// with the same alias (or title for oneliners) of the lower layers.
 func Load(configPath string) (Config, error) {
-	configBytes, err := os.ReadFile(configPath)
+	config := Config{
+		Extensions: make(map[string]ExtensionConfig),
+		Keymap:     make(map[string]Keys),
+		sources:    make(map[string]string),
+	}
+
+	if configPath != GlobalPath {
+		if _, err := os.Stat(GlobalPath); err == nil {
+			if err := config.load(GlobalPath, nil); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+
+	if err := config.load(configPath, nil); err != nil {
+		return Config{}, err
+	}
+
+	for alias, extension := range config.Extensions {
+		extension.secrets = config.SecretStore()
+		config.Extensions[alias] = extension
+	}
+
+	base, err := json.Marshal(config)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	config.path = configPath
+	config.base = base
+
+	return config, nil
+}
+
+// load merges the content of a config file and of its includes into the config.
+func (cfg *Config) load(configPath string, visited []string) error {
+	for _, path := range visited {
+		if path == configPath {
+			return fmt.Errorf("config include cycle: %s", strings.Join(append(visited, configPath), " -> "))
+		}
+	}
+	visited = append(visited, configPath)
+
+	file, err := readFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	for _, include := range file.Include {
+		includePath := resolvePath(filepath.Dir(configPath), include)
+		if err := cfg.load(includePath, visited); err != nil {
+			return err
+		}
+
+		// the includes of every layer are kept, resolved from the file declaring them
+		if !slices.Contains(cfg.Include, includePath) {
+			cfg.Include = append(cfg.Include, includePath)
+		}
+	}
+
+	for alias, extension := range file.Extensions {
+		extension.source = configPath
+		cfg.Extensions[alias] = extension
+		cfg.sources[extensionKey(alias)] = configPath
+	}
+
+	for _, oneliner := range file.Oneliners {
+		cfg.setOneliner(oneliner)
+		cfg.sources[onelinerKey(oneliner.Title)] = configPath
+	}
+
+	if file.Secrets != nil {
+		cfg.Secrets = file.Secrets
+	}
+
+	if file.Mouse != nil {
+		cfg.Mouse = file.Mouse
+	}
+
+	if file.Theme != nil {
+		cfg.setTheme(*file.Theme, filepath.Dir(configPath))
+	}
+
+	for command, keys := range file.Keymap {
+		cfg.Keymap[command] = keys
+	}
+
+	return nil
+}
+
+func readFile(configPath string) (Config, error) {
+	content, err := os.ReadFile(configPath)
 	if err != nil {
 		return Config{}, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if err := schemas.ValidateConfig(configBytes); err != nil {
-		return Config{}, fmt.Errorf("invalid config: %w", err)
+	return parseConfig(configPath, content)
+}
+
+// parseConfig parses the content of a config file, in any of the supported formats.
+func parseConfig(configPath string, content []byte) (Config, error) {
+	configBytes, err := ToJSON(configPath, content)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", configPath, err)
 	}
 
-	// set default values for configs
-	config := Config{
-		Extensions: make(map[string]ExtensionConfig),
+	if err := schemas.ValidateConfig(configBytes); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", configPath, err)
 	}
 
+	var config Config
 	if err := json.Unmarshal(configBytes, &config); err != nil {
 		return Config{}, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
-	config.path = configPath
+
+	if config.Extensions == nil {
+		config.Extensions = make(map[string]ExtensionConfig)
+	}
 
 	return config, nil
 }
 
+// setOneliner replaces the oneliner with the same title, or appends it.
+func (cfg *Config) setOneliner(oneliner Oneliner) {
+	for i, existing := range cfg.Oneliners {
+		if existing.Title == oneliner.Title {
+			cfg.Oneliners[i] = oneliner
+			return
+		}
+	}
+
+	cfg.Oneliners = append(cfg.Oneliners, oneliner)
+}
+
+func (cfg *Config) removeOneliner(title string) {
+	for i, existing := range cfg.Oneliners {
+		if existing.Title == title {
+			cfg.Oneliners = append(cfg.Oneliners[:i], cfg.Oneliners[i+1:]...)
+			return
+		}
+	}
+}
+
+// Save writes the changes made to the config since it was loaded. Each change is written to the file
+// defining the entry, new entries are added to the config file. The files are read again before writing,
+// so that the modifications made in the meantime by other processes are kept.
 func (c Config) Save() error {
-	f, err := os.Create(c.path)
+	if c.path == "" {
+		return fmt.Errorf("the config was not loaded from a file, it can't be saved")
+	}
+
+	changes, err := c.changes()
+	if err != nil {
+		return err
+	}
+
+	for path, changes := range changes {
+		if err := saveChanges(path, changes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// change is the new value of an extension or a oneliner, nil values are removals.
+type change struct {
+	alias     string
+	extension *ExtensionConfig
+	title     string
+	oneliner  *Oneliner
+}
+
+// changes returns the changes made since the config was loaded, grouped by the file they should be written to.
+func (c Config) changes() (map[string][]change, error) {
+	var base Config
+	if err := json.Unmarshal(c.base, &base); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	changes := make(map[string][]change)
+	for alias, extension := range c.Extensions {
+		if previous, ok := base.Extensions[alias]; !ok || !jsonEqual(extension, previous) {
+			extension := extension
+			// renamed extensions stay in the file defining them
+			source := extension.source
+			if source == "" {
+				source = c.source(extensionKey(alias))
+			}
+			changes[source] = append(changes[source], change{alias: alias, extension: &extension})
+		}
+	}
+
+	for alias := range base.Extensions {
+		if _, ok := c.Extensions[alias]; !ok {
+			source := c.source(extensionKey(alias))
+			changes[source] = append(changes[source], change{alias: alias})
+		}
+	}
+
+	previous := make(map[string]Oneliner)
+	for _, oneliner := range base.Oneliners {
+		previous[oneliner.Title] = oneliner
+	}
+
+	titles := make(map[string]bool)
+	for _, oneliner := range c.Oneliners {
+		titles[oneliner.Title] = true
+		if p, ok := previous[oneliner.Title]; !ok || !jsonEqual(oneliner, p) {
+			oneliner := oneliner
+			source := c.source(onelinerKey(oneliner.Title))
+			changes[source] = append(changes[source], change{title: oneliner.Title, oneliner: &oneliner})
+		}
+	}
+
+	for title := range previous {
+		if !titles[title] {
+			source := c.source(onelinerKey(title))
+			changes[source] = append(changes[source], change{title: title})
+		}
+	}
+
+	return changes, nil
+}
+
+func saveChanges(path string, changes []change) error {
+	unlock, err := utils.LockFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to lock config: %w", err)
+	}
+	defer unlock()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	target, err := parseConfig(path, content)
+	if err != nil {
+		return err
+	}
+
+	// the file is edited in place to preserve its format and comments
+	doc, err := parseDocument(path, content)
 	if err != nil {
-		return fmt.Errorf("failed to open config: %w", err)
+		return err
+	}
+
+	var onelinersChanged bool
+	for _, change := range changes {
+		switch {
+		case change.extension != nil:
+			err = doc.setMember("extensions", change.alias, *change.extension)
+		case change.alias != "":
+			err = doc.deleteMember("extensions", change.alias)
+		case change.oneliner != nil:
+			target.setOneliner(*change.oneliner)
+			onelinersChanged = true
+		default:
+			target.removeOneliner(change.title)
+			onelinersChanged = true
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to edit config: %w", err)
+		}
 	}
-	defer f.Close()
 
-	encoder := json.NewEncoder(f)
-	encoder.SetIndent("", "  ")
-	encoder.SetEscapeHTML(false)
-	if err := encoder.Encode(c); err != nil {
+	if onelinersChanged {
+		if err := doc.set("oneliners", target.Oneliners); err != nil {
+			return fmt.Errorf("failed to edit config: %w", err)
+		}
+	}
+
+	bts, err := doc.bytes()
+	if err != nil {
 		return fmt.Errorf("failed to encode config: %w", err)
 	}
 
+	if err := utils.WriteFileAtomic(path, bts, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
 	return nil
 }
+
+func jsonEqual(a any, b any) bool {
+	aBytes, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	bBytes, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(aBytes, bBytes)
+}
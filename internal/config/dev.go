@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LoadDev loads the config of an extension run in dev mode, stored in its own file so that the
+// preferences entered while developing it are kept. The file is created from the given extension
+// config if it does not exist. The secret store of cfg is used for the secret preferences.
+func LoadDev(cfg Config, devPath string, alias string, extension ExtensionConfig) (Config, error) {
+	if _, err := os.Stat(devPath); os.IsNotExist(err) {
+		content, err := json.MarshalIndent(Config{Extensions: map[string]ExtensionConfig{alias: extension}}, "", "  ")
+		if err != nil {
+			return Config{}, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(devPath), 0755); err != nil {
+			return Config{}, err
+		}
+
+		if err := os.WriteFile(devPath, content, 0600); err != nil {
+			return Config{}, fmt.Errorf("failed to write dev config: %w", err)
+		}
+	}
+
+	dev := Config{
+		Extensions: make(map[string]ExtensionConfig),
+		Keymap:     make(map[string]Keys),
+		sources:    make(map[string]string),
+	}
+
+	if err := dev.load(devPath, nil); err != nil {
+		return Config{}, err
+	}
+
+	stored, ok := dev.Extensions[alias]
+	if !ok {
+		return Config{}, fmt.Errorf("extension %s not found in dev config %s", alias, devPath)
+	}
+
+	stored.secrets = cfg.SecretStore()
+	dev.Extensions = map[string]ExtensionConfig{alias: stored}
+	dev.Secrets = cfg.Secrets
+
+	base, err := json.Marshal(dev)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	dev.path = devPath
+	dev.base = base
+	return dev, nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configNames are the names of the config files, in order of precedence.
+var configNames = []string{"sunbeam.json", "sunbeam.jsonc", "sunbeam.yaml", "sunbeam.yml"}
+
+func isYAML(configPath string) bool {
+	ext := filepath.Ext(configPath)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// ToJSON converts the content of a config file to json, based on the extension of the file.
+// Json files are parsed as jsonc, so comments and trailing commas are allowed.
+func ToJSON(configPath string, content []byte) ([]byte, error) {
+	if isYAML(configPath) {
+		var v any
+		if err := yaml.Unmarshal(content, &v); err != nil {
+			return nil, fmt.Errorf("failed to parse yaml: %w", err)
+		}
+
+		// empty documents are empty configs
+		if v == nil {
+			v = map[string]any{}
+		}
+
+		return json.Marshal(v)
+	}
+
+	return standardizeJSONC(content)
+}
+
+// document is a config file edited in place, so that the parts that are not modified
+// keep their formatting and comments.
+type document interface {
+	// set replaces the value of a top-level key.
+	set(key string, value any) error
+	// setMember replaces the value of a member of a top-level object.
+	setMember(key string, member string, value any) error
+	// deleteMember removes a member of a top-level object.
+	deleteMember(key string, member string) error
+	bytes() ([]byte, error)
+}
+
+func parseDocument(configPath string, content []byte) (document, error) {
+	if isYAML(configPath) {
+		return parseYAMLDocument(content)
+	}
+
+	return parseJSONCDocument(content)
+}
+
+// toGeneric converts a value to the generic representation of its json encoding,
+// so that the json tags are used whatever the format of the document.
+func toGeneric(value any) (any, error) {
+	bts, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var v any
+	if err := json.Unmarshal(bts, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
@@ -0,0 +1,493 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// standardizeJSONC replaces the comments and trailing commas of a jsonc document with spaces.
+// The offsets of the standard document match the ones of the original document.
+func standardizeJSONC(src []byte) ([]byte, error) {
+	res := make([]byte, len(src))
+	copy(res, src)
+
+	for i := 0; i < len(res); i++ {
+		switch {
+		case res[i] == '"':
+			end, err := scanString(res, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end - 1
+		case bytes.HasPrefix(res[i:], []byte("//")):
+			for i < len(res) && res[i] != '\n' {
+				res[i] = ' '
+				i++
+			}
+		case bytes.HasPrefix(res[i:], []byte("/*")):
+			end := bytes.Index(res[i+2:], []byte("*/"))
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+
+			for j := i; j < i+2+end+2; j++ {
+				if res[j] != '\n' {
+					res[j] = ' '
+				}
+			}
+			i = i + 2 + end + 1
+		}
+	}
+
+	for i := 0; i < len(res); i++ {
+		switch res[i] {
+		case '"':
+			end, err := scanString(res, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end - 1
+		case ',':
+			next := skipWhitespace(res, i+1)
+			if next < len(res) && (res[next] == '}' || res[next] == ']') {
+				res[i] = ' '
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func skipWhitespace(src []byte, i int) int {
+	for i < len(src) && (src[i] == ' ' || src[i] == '\t' || src[i] == '\n' || src[i] == '\r') {
+		i++
+	}
+
+	return i
+}
+
+// scanString returns the offset following the string starting at the given offset.
+func scanString(src []byte, i int) (int, error) {
+	for i = i + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated string")
+}
+
+// scanValue returns the offset following the value starting at the given offset.
+func scanValue(src []byte, i int) (int, error) {
+	if i >= len(src) {
+		return 0, fmt.Errorf("unexpected end of input")
+	}
+
+	switch src[i] {
+	case '"':
+		return scanString(src, i)
+	case '{', '[':
+		depth := 0
+		for ; i < len(src); i++ {
+			switch src[i] {
+			case '"':
+				end, err := scanString(src, i)
+				if err != nil {
+					return 0, err
+				}
+				i = end - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1, nil
+				}
+			}
+		}
+
+		return 0, fmt.Errorf("unexpected end of input")
+	default:
+		for i < len(src) && !strings.ContainsRune(",}] \t\r\n", rune(src[i])) {
+			i++
+		}
+
+		return i, nil
+	}
+}
+
+type jsoncMember struct {
+	key        string
+	keyStart   int
+	valueStart int
+	valueEnd   int
+}
+
+type jsoncObject struct {
+	start   int
+	end     int
+	members []jsoncMember
+}
+
+func (o jsoncObject) member(key string) (jsoncMember, bool) {
+	for _, member := range o.members {
+		if member.key == key {
+			return member, true
+		}
+	}
+
+	return jsoncMember{}, false
+}
+
+// parseObject returns the position of the members of the object starting at the given offset.
+func parseObject(src []byte, i int) (jsoncObject, error) {
+	if i >= len(src) || src[i] != '{' {
+		return jsoncObject{}, fmt.Errorf("expected an object at offset %d", i)
+	}
+
+	object := jsoncObject{start: i}
+	i = skipWhitespace(src, i+1)
+	for i < len(src) && src[i] != '}' {
+		keyEnd, err := scanString(src, i)
+		if err != nil {
+			return jsoncObject{}, err
+		}
+
+		var key string
+		if err := json.Unmarshal(src[i:keyEnd], &key); err != nil {
+			return jsoncObject{}, fmt.Errorf("invalid key at offset %d: %w", i, err)
+		}
+
+		colon := skipWhitespace(src, keyEnd)
+		if colon >= len(src) || src[colon] != ':' {
+			return jsoncObject{}, fmt.Errorf("expected ':' at offset %d", colon)
+		}
+
+		valueStart := skipWhitespace(src, colon+1)
+		valueEnd, err := scanValue(src, valueStart)
+		if err != nil {
+			return jsoncObject{}, err
+		}
+
+		object.members = append(object.members, jsoncMember{
+			key:        key,
+			keyStart:   i,
+			valueStart: valueStart,
+			valueEnd:   valueEnd,
+		})
+
+		i = skipWhitespace(src, valueEnd)
+		if i < len(src) && src[i] == ',' {
+			i = skipWhitespace(src, i+1)
+		}
+	}
+
+	if i >= len(src) {
+		return jsoncObject{}, fmt.Errorf("unexpected end of input")
+	}
+
+	object.end = i
+	return object, nil
+}
+
+type jsoncDocument struct {
+	src []byte
+	// indentation unit of the document
+	indent string
+}
+
+func parseJSONCDocument(src []byte) (*jsoncDocument, error) {
+	doc := &jsoncDocument{src: src, indent: "  "}
+	for _, line := range strings.Split(string(src), "\n") {
+		if strings.HasPrefix(line, "\t") {
+			doc.indent = "\t"
+			break
+		}
+
+		if trimmed := strings.TrimLeft(line, " "); trimmed != line && trimmed != "" {
+			doc.indent = line[:len(line)-len(trimmed)]
+			break
+		}
+	}
+
+	if _, err := doc.root(); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func (d *jsoncDocument) standard() ([]byte, error) {
+	return standardizeJSONC(d.src)
+}
+
+func (d *jsoncDocument) root() (jsoncObject, error) {
+	std, err := d.standard()
+	if err != nil {
+		return jsoncObject{}, err
+	}
+
+	return parseObject(std, skipWhitespace(std, 0))
+}
+
+// lineIndent returns the indentation of the line containing the given offset, and whether
+// only whitespace precedes the offset on its line.
+func (d *jsoncDocument) lineIndent(offset int) (string, bool) {
+	lineStart := bytes.LastIndexByte(d.src[:offset], '\n') + 1
+	prefix := string(d.src[lineStart:offset])
+	indent := prefix[:len(prefix)-len(strings.TrimLeft(prefix, " \t"))]
+	return indent, len(indent) == len(prefix)
+}
+
+func (d *jsoncDocument) marshal(value any, indent string, multiline bool) (string, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if multiline {
+		encoder.SetIndent(indent, d.indent)
+	}
+
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+func (d *jsoncDocument) replace(start int, end int, text string) {
+	d.src = append(d.src[:start:start], append([]byte(text), d.src[end:]...)...)
+}
+
+func (d *jsoncDocument) replaceValue(member jsoncMember, value any) error {
+	indent, multiline := d.lineIndent(member.keyStart)
+	text, err := d.marshal(value, indent, multiline)
+	if err != nil {
+		return err
+	}
+
+	d.replace(member.valueStart, member.valueEnd, text)
+	return nil
+}
+
+func (d *jsoncDocument) insertMember(object jsoncObject, key string, value any) error {
+	keyBytes, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	if len(object.members) == 0 {
+		objectIndent, _ := d.lineIndent(object.start)
+		indent := objectIndent + d.indent
+		text, err := d.marshal(value, indent, true)
+		if err != nil {
+			return err
+		}
+
+		d.replace(object.start+1, object.end, fmt.Sprintf("\n%s%s: %s\n%s", indent, keyBytes, text, objectIndent))
+		return nil
+	}
+
+	last := object.members[len(object.members)-1]
+	indent, multiline := d.lineIndent(last.keyStart)
+	text, err := d.marshal(value, indent, multiline)
+	if err != nil {
+		return err
+	}
+
+	if !multiline {
+		d.replace(last.valueEnd, last.valueEnd, fmt.Sprintf(", %s: %s", keyBytes, text))
+		return nil
+	}
+
+	std, err := d.standard()
+	if err != nil {
+		return err
+	}
+
+	// the new member goes after the comment following the last member on its line
+	end, ok := lineEnd(std, last.valueEnd)
+	if !ok {
+		d.replace(last.valueEnd, last.valueEnd, fmt.Sprintf(",\n%s%s: %s", indent, keyBytes, text))
+		return nil
+	}
+
+	// a trailing comma is kept after the new member
+	if d.commaAfter(last.valueEnd) != -1 {
+		d.replace(end, end, fmt.Sprintf("\n%s%s: %s,", indent, keyBytes, text))
+		return nil
+	}
+
+	d.replace(end, end, fmt.Sprintf("\n%s%s: %s", indent, keyBytes, text))
+	d.replace(last.valueEnd, last.valueEnd, ",")
+	return nil
+}
+
+// lineEnd returns the end of the line containing the given offset of a standardized document,
+// if only whitespace follows the offset on its line.
+func lineEnd(std []byte, offset int) (int, bool) {
+	for i := offset; i < len(std); i++ {
+		switch std[i] {
+		case ' ', '\t':
+		case '\r', '\n':
+			return i, true
+		default:
+			return 0, false
+		}
+	}
+
+	return len(std), true
+}
+
+// commaAfter returns the offset of the comma following the given offset, or -1 if there is none.
+func (d *jsoncDocument) commaAfter(offset int) int {
+	i := offset
+	for i < len(d.src) && (d.src[i] == ' ' || d.src[i] == '\t') {
+		i++
+	}
+
+	if i < len(d.src) && d.src[i] == ',' {
+		return i
+	}
+
+	return -1
+}
+
+func (d *jsoncDocument) set(key string, value any) error {
+	root, err := d.root()
+	if err != nil {
+		return err
+	}
+
+	if member, ok := root.member(key); ok {
+		return d.replaceValue(member, value)
+	}
+
+	return d.insertMember(root, key, value)
+}
+
+// object returns the top-level object stored at the given key.
+func (d *jsoncDocument) object(key string) (jsoncObject, bool, error) {
+	root, err := d.root()
+	if err != nil {
+		return jsoncObject{}, false, err
+	}
+
+	member, ok := root.member(key)
+	if !ok {
+		return jsoncObject{}, false, nil
+	}
+
+	std, err := d.standard()
+	if err != nil {
+		return jsoncObject{}, false, err
+	}
+
+	object, err := parseObject(std, member.valueStart)
+	if err != nil {
+		return jsoncObject{}, false, err
+	}
+
+	return object, true, nil
+}
+
+func (d *jsoncDocument) setMember(key string, member string, value any) error {
+	object, ok, err := d.object(key)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return d.set(key, map[string]any{member: value})
+	}
+
+	if existing, ok := object.member(member); ok {
+		return d.replaceValue(existing, value)
+	}
+
+	return d.insertMember(object, member, value)
+}
+
+func (d *jsoncDocument) deleteMember(key string, member string) error {
+	object, ok, err := d.object(key)
+	if err != nil || !ok {
+		return err
+	}
+
+	existing, ok := object.member(member)
+	if !ok {
+		return nil
+	}
+
+	if len(object.members) == 1 {
+		d.replace(object.start+1, object.end, "")
+		return nil
+	}
+
+	std, err := d.standard()
+	if err != nil {
+		return err
+	}
+
+	next := skipWhitespace(std, existing.valueEnd)
+	hasNext := next < len(std) && std[next] == ','
+	if _, ownLine := d.lineIndent(existing.keyStart); !ownLine {
+		// remove the following comma, or the preceding one for the last member
+		if hasNext {
+			d.replace(existing.keyStart, next+1, "")
+			return nil
+		}
+
+		previous := d.previousMember(object, existing)
+		d.replace(previous.valueEnd, existing.valueEnd, "")
+		return nil
+	}
+
+	// remove the lines of the member, along with the comment following it
+	start, end := bytes.LastIndexByte(d.src[:existing.keyStart], '\n')+1, existing.valueEnd
+	if hasNext {
+		end = next + 1
+	}
+
+	if lineEnd, ok := lineEnd(std, end); ok {
+		end = lineEnd
+		if end < len(d.src) && d.src[end] == '\r' {
+			end++
+		}
+		if end < len(d.src) && d.src[end] == '\n' {
+			end++
+		}
+	}
+
+	trailingComma := d.commaAfter(existing.valueEnd) != -1
+	d.replace(start, end, "")
+
+	// the previous member becomes the last one, it keeps its comma only if the member had a trailing one
+	if !hasNext && !trailingComma {
+		previous := d.previousMember(object, existing)
+		if comma := d.commaAfter(previous.valueEnd); comma != -1 {
+			d.replace(comma, comma+1, "")
+		}
+	}
+
+	return nil
+}
+
+func (d *jsoncDocument) previousMember(object jsoncObject, member jsoncMember) jsoncMember {
+	previous := object.members[0]
+	for _, m := range object.members {
+		if m.keyStart < member.keyStart {
+			previous = m
+		}
+	}
+
+	return previous
+}
+
+func (d *jsoncDocument) bytes() ([]byte, error) {
+	return d.src, nil
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONCDocument(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      string
+		edit     func(d *jsoncDocument) error
+		expected string
+	}{
+		{
+			name: "set existing key",
+			src:  "{\n  // mouse support\n  \"mouse\": false\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.set("mouse", true)
+			},
+			expected: "{\n  // mouse support\n  \"mouse\": true\n}\n",
+		},
+		{
+			name: "set new key",
+			src:  "{\n  \"mouse\": true\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.set("theme", map[string]any{"accent": "12"})
+			},
+			expected: "{\n  \"mouse\": true,\n  \"theme\": {\n    \"accent\": \"12\"\n  }\n}\n",
+		},
+		{
+			name: "set key of empty document",
+			src:  "{}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.set("mouse", true)
+			},
+			expected: "{\n  \"mouse\": true\n}\n",
+		},
+		{
+			name: "insert member after comment",
+			src:  "{\n  \"extensions\": {\n    \"a\": {}, // first\n    \"b\": {} // second\n  }\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.setMember("extensions", "c", map[string]any{})
+			},
+			expected: "{\n  \"extensions\": {\n    \"a\": {}, // first\n    \"b\": {}, // second\n    \"c\": {}\n  }\n}\n",
+		},
+		{
+			name: "insert member after trailing comma",
+			src:  "{\n  \"extensions\": {\n    \"a\": {},\n  },\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.setMember("extensions", "b", map[string]any{})
+			},
+			expected: "{\n  \"extensions\": {\n    \"a\": {},\n    \"b\": {},\n  },\n}\n",
+		},
+		{
+			name: "insert member in single line object",
+			src:  "{\"extensions\": {\"a\": {}}}",
+			edit: func(d *jsoncDocument) error {
+				return d.setMember("extensions", "b", map[string]any{})
+			},
+			expected: "{\"extensions\": {\"a\": {}, \"b\": {}}}",
+		},
+		{
+			name: "insert member with tab indentation",
+			src:  "{\n\t\"extensions\": {\n\t\t\"a\": {}\n\t}\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.setMember("extensions", "b", map[string]any{"origin": "./b.sh"})
+			},
+			expected: "{\n\t\"extensions\": {\n\t\t\"a\": {},\n\t\t\"b\": {\n\t\t\t\"origin\": \"./b.sh\"\n\t\t}\n\t}\n}\n",
+		},
+		{
+			name: "replace member",
+			src:  "{\n  \"extensions\": {\n    \"a\": {\"origin\": \"./a.sh\"} // first\n  }\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.setMember("extensions", "a", map[string]any{"origin": "./b.sh"})
+			},
+			expected: "{\n  \"extensions\": {\n    \"a\": {\n      \"origin\": \"./b.sh\"\n    } // first\n  }\n}\n",
+		},
+		{
+			name: "delete first member with comment",
+			src:  "{\n  \"extensions\": {\n    \"a\": {}, // first\n    \"b\": {} // second\n  }\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.deleteMember("extensions", "a")
+			},
+			expected: "{\n  \"extensions\": {\n    \"b\": {} // second\n  }\n}\n",
+		},
+		{
+			name: "delete last member with comment",
+			src:  "{\n  \"extensions\": {\n    \"a\": {}, // first\n    \"b\": {} // second\n  }\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.deleteMember("extensions", "b")
+			},
+			expected: "{\n  \"extensions\": {\n    \"a\": {} // first\n  }\n}\n",
+		},
+		{
+			name: "delete last member with trailing comma",
+			src:  "{\n  \"extensions\": {\n    \"a\": {},\n    \"b\": {},\n  }\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.deleteMember("extensions", "b")
+			},
+			expected: "{\n  \"extensions\": {\n    \"a\": {},\n  }\n}\n",
+		},
+		{
+			name: "delete multiline member",
+			src:  "{\n  \"extensions\": {\n    \"a\": {\n      \"origin\": \"./a.sh\"\n    },\n    \"b\": {}\n  }\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.deleteMember("extensions", "a")
+			},
+			expected: "{\n  \"extensions\": {\n    \"b\": {}\n  }\n}\n",
+		},
+		{
+			name: "delete member of single line object",
+			src:  "{\"extensions\": {\"a\": {}, \"b\": {}}}",
+			edit: func(d *jsoncDocument) error {
+				return d.deleteMember("extensions", "b")
+			},
+			expected: "{\"extensions\": {\"a\": {}}}",
+		},
+		{
+			name: "delete only member",
+			src:  "{\n  \"extensions\": {\n    \"a\": {}\n  }\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.deleteMember("extensions", "a")
+			},
+			expected: "{\n  \"extensions\": {}\n}\n",
+		},
+		{
+			name: "delete missing member",
+			src:  "{\n  // no extensions\n}\n",
+			edit: func(d *jsoncDocument) error {
+				return d.deleteMember("extensions", "a")
+			},
+			expected: "{\n  // no extensions\n}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := parseJSONCDocument([]byte(tc.src))
+			if err != nil {
+				t.Fatalf("failed to parse document: %v", err)
+			}
+
+			if err := tc.edit(d); err != nil {
+				t.Fatalf("failed to edit document: %v", err)
+			}
+
+			actual, err := d.bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(actual) != tc.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+
+			std, err := standardizeJSONC(actual)
+			if err != nil || !json.Valid(std) {
+				t.Fatalf("the edited document is invalid: %s", actual)
+			}
+		})
+	}
+}
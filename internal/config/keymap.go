@@ -0,0 +1,22 @@
+package config
+
+import "encoding/json"
+
+// Keys are the keys bound to a command of the keymap. They can be written as a single string.
+type Keys []string
+
+func (k *Keys) UnmarshalJSON(data []byte) error {
+	var key string
+	if err := json.Unmarshal(data, &key); err == nil {
+		*k = Keys{key}
+		return nil
+	}
+
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+
+	*k = keys
+	return nil
+}
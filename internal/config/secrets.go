@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pomdtr/sunbeam/internal/utils"
+)
+
+// SecretsPath is the file storing the secret preferences when no store command is configured.
+var SecretsPath = filepath.Join(utils.ConfigDir(), "secrets.json")
+
+// SecretStore stores the preferences marked as secret in the manifests.
+// By default, secrets are stored in a file only readable by the user. If a command is set,
+// it is called with `get <key>` to read a secret from its stdout, and with `set <key>` to write
+// the secret passed to its stdin.
+type SecretStore struct {
+	Command string `json:"command,omitempty"`
+}
+
+// SecretKey returns the key used to store a preference of an extension.
+func SecretKey(alias string, name string) string {
+	return fmt.Sprintf("%s.%s", alias, name)
+}
+
+// SecretReference returns the reference to a secret, to be stored in the config instead of its value.
+func SecretReference(key string) string {
+	return fmt.Sprintf("${secret:%s}", key)
+}
+
+func (s SecretStore) Get(key string) (string, error) {
+	if s.Command != "" {
+		var stderr bytes.Buffer
+		cmd := s.cmd("get", key)
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%s", msg)
+			}
+			return "", fmt.Errorf("failed to get secret %s: %w", key, err)
+		}
+
+		return strings.TrimRight(string(output), "\n"), nil
+	}
+
+	secrets, err := readSecrets()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", key)
+	}
+
+	return secret, nil
+}
+
+func (s SecretStore) Set(key string, value string) error {
+	if s.Command != "" {
+		var stderr bytes.Buffer
+		cmd := s.cmd("set", key)
+		cmd.Stdin = strings.NewReader(value)
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%s", msg)
+			}
+			return fmt.Errorf("failed to set secret %s: %w", key, err)
+		}
+
+		return nil
+	}
+
+	unlock, err := utils.LockFile(SecretsPath)
+	if err != nil {
+		return fmt.Errorf("failed to lock secrets: %w", err)
+	}
+	defer unlock()
+
+	secrets, err := readSecrets()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+
+	bts, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(SecretsPath, bts, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets: %w", err)
+	}
+
+	return nil
+}
+
+func (s SecretStore) cmd(args ...string) *exec.Cmd {
+	// the arguments are passed as positional parameters, so that keys are never interpreted by the shell
+	return exec.Command("sh", append([]string{"-c", s.Command + ` "$@"`, "sh"}, args...)...)
+}
+
+func readSecrets() (map[string]string, error) {
+	bts, err := os.ReadFile(SecretsPath)
+	if os.IsNotExist(err) {
+		return make(map[string]string), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+
+	var secrets map[string]string
+	if err := json.Unmarshal(bts, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to decode secrets: %w", err)
+	}
+
+	if secrets == nil {
+		secrets = make(map[string]string)
+	}
+
+	return secrets, nil
+}
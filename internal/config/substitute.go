@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// referenceRegexp matches the ${env:NAME}, ${cmd:command} and ${secret:key} references of config values.
+var referenceRegexp = regexp.MustCompile(`\$\{(env|cmd|secret):([^}]*)\}`)
+
+// commandWaitDelay bounds the time spent waiting for the output of a cancelled command,
+// the processes it spawned can keep the pipe open.
+const commandWaitDelay = time.Second
+
+// IsReference checks if a value only contains a reference.
+func IsReference(value any) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	loc := referenceRegexp.FindStringIndex(s)
+	return loc != nil && loc[0] == 0 && loc[1] == len(s)
+}
+
+// Substitute replaces the references of a config value by the content of the environment variable,
+// the output of the command or the secret. Values that are not strings are returned unchanged.
+// The commands are killed once the context is done.
+func (e ExtensionConfig) Substitute(ctx context.Context, value any) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+
+	var substituteErr error
+	res := referenceRegexp.ReplaceAllStringFunc(s, func(reference string) string {
+		if substituteErr != nil {
+			return reference
+		}
+
+		matches := referenceRegexp.FindStringSubmatch(reference)
+		kind, arg := matches[1], matches[2]
+		switch kind {
+		case "secret":
+			secret, err := e.secrets.Get(arg)
+			if err != nil {
+				substituteErr = err
+				return reference
+			}
+
+			return secret
+		case "env":
+			env, ok := os.LookupEnv(arg)
+			if !ok {
+				substituteErr = fmt.Errorf("environment variable %s is not set", arg)
+				return reference
+			}
+
+			return env
+		default:
+			var stderr bytes.Buffer
+			cmd := exec.CommandContext(ctx, "sh", "-c", arg)
+			cmd.Stderr = &stderr
+			cmd.WaitDelay = commandWaitDelay
+			output, err := cmd.Output()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				substituteErr = fmt.Errorf("secret command %s timed out", arg)
+				return reference
+			} else if err != nil {
+				if msg := strings.TrimSpace(stderr.String()); msg != "" {
+					err = fmt.Errorf("%s", msg)
+				}
+				substituteErr = fmt.Errorf("failed to run %s: %w", arg, err)
+				return reference
+			}
+
+			return strings.TrimRight(string(output), "\n")
+		}
+	})
+
+	if substituteErr != nil {
+		return nil, substituteErr
+	}
+
+	return res, nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubstitute(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands are run by sh")
+	}
+	t.Setenv("SUNBEAM_TEST_TOKEN", "secret")
+
+	testCases := []struct {
+		name     string
+		value    any
+		expected any
+		err      string
+	}{
+		{name: "not a string", value: 42.0, expected: 42.0},
+		{name: "no reference", value: "token", expected: "token"},
+		{name: "env reference", value: "${env:SUNBEAM_TEST_TOKEN}", expected: "secret"},
+		{name: "missing env", value: "${env:SUNBEAM_TEST_MISSING}", err: "environment variable SUNBEAM_TEST_MISSING is not set"},
+		{name: "cmd reference", value: "Bearer ${cmd:echo secret}", expected: "Bearer secret"},
+		{name: "failing cmd", value: "${cmd:echo invalid >&2; exit 1}", err: "invalid"},
+		{name: "hung cmd", value: "${cmd:sleep 10}", err: "secret command sleep 10 timed out"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			actual, err := ExtensionConfig{}.Substitute(ctx, tc.value)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if actual != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import "path/filepath"
+
+type Theme struct {
+	Name      string `json:"name,omitempty"`
+	Accent    string `json:"accent,omitempty"`
+	Faint     string `json:"faint,omitempty"`
+	Separator string `json:"separator,omitempty"`
+	Markdown  string `json:"markdown,omitempty"`
+}
+
+// IsMarkdownFile reports whether the markdown style is a file rather than the name of a built-in style.
+func (t Theme) IsMarkdownFile() bool {
+	return filepath.Ext(t.Markdown) == ".json"
+}
+
+// setTheme overrides the fields of the theme set by the upper layer.
+func (cfg *Config) setTheme(theme Theme, dir string) {
+	if cfg.Theme == nil {
+		cfg.Theme = &Theme{}
+	}
+
+	if theme.Name != "" {
+		cfg.Theme.Name = theme.Name
+	}
+
+	if theme.Accent != "" {
+		cfg.Theme.Accent = theme.Accent
+	}
+
+	if theme.Faint != "" {
+		cfg.Theme.Faint = theme.Faint
+	}
+
+	if theme.Separator != "" {
+		cfg.Theme.Separator = theme.Separator
+	}
+
+	if theme.Markdown != "" {
+		// style files are relative to the config defining them
+		if theme.IsMarkdownFile() {
+			theme.Markdown = resolvePath(dir, theme.Markdown)
+		}
+		cfg.Theme.Markdown = theme.Markdown
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+type yamlDocument struct {
+	node yaml.Node
+}
+
+func parseYAMLDocument(src []byte) (*yamlDocument, error) {
+	var doc yamlDocument
+	if err := yaml.Unmarshal(src, &doc.node); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %w", err)
+	}
+
+	// empty documents are empty configs
+	if doc.node.Kind == 0 {
+		doc.node = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+
+	if len(doc.node.Content) == 0 || doc.node.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config must be a mapping")
+	}
+
+	return &doc, nil
+}
+
+func (d *yamlDocument) root() *yaml.Node {
+	return d.node.Content[0]
+}
+
+// lookup returns the index of the value of a key in a mapping node, or -1.
+func lookup(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+func encodeYAML(value any) (*yaml.Node, error) {
+	generic, err := toGeneric(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := node.Encode(generic); err != nil {
+		return nil, err
+	}
+
+	return &node, nil
+}
+
+func setYAMLKey(mapping *yaml.Node, key string, value *yaml.Node) {
+	if i := lookup(mapping, key); i != -1 {
+		// keep the comments attached to the previous value
+		value.HeadComment = mapping.Content[i].HeadComment
+		value.LineComment = mapping.Content[i].LineComment
+		value.FootComment = mapping.Content[i].FootComment
+		mapping.Content[i] = value
+		return
+	}
+
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func (d *yamlDocument) set(key string, value any) error {
+	node, err := encodeYAML(value)
+	if err != nil {
+		return err
+	}
+
+	setYAMLKey(d.root(), key, node)
+	return nil
+}
+
+func (d *yamlDocument) setMember(key string, member string, value any) error {
+	i := lookup(d.root(), key)
+	if i == -1 || d.root().Content[i].Kind != yaml.MappingNode {
+		return d.set(key, map[string]any{member: value})
+	}
+
+	node, err := encodeYAML(value)
+	if err != nil {
+		return err
+	}
+
+	setYAMLKey(d.root().Content[i], member, node)
+	return nil
+}
+
+func (d *yamlDocument) deleteMember(key string, member string) error {
+	i := lookup(d.root(), key)
+	if i == -1 {
+		return nil
+	}
+
+	mapping := d.root().Content[i]
+	if j := lookup(mapping, member); j != -1 {
+		mapping.Content = append(mapping.Content[:j-1], mapping.Content[j+1:]...)
+	}
+
+	return nil
+}
+
+func (d *yamlDocument) bytes() ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&d.node); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -1,6 +1,7 @@
 package extensions
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
@@ -13,6 +14,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/acarl005/stripansi"
 	"github.com/pomdtr/sunbeam/internal/config"
@@ -34,6 +36,36 @@ func (e ExtensionMap) List() []Extension {
 type Extension struct {
 	Manifest   sunbeam.Manifest
 	Entrypoint string `json:"entrypoint"`
+	Config     config.ExtensionConfig
+	// DisableLogs keeps the invocations out of the extension logs, like the runs of the test fixtures
+	DisableLogs bool `json:"-"`
+}
+
+// DefaultTimeout is used when neither the manifest nor the config specify a timeout.
+const DefaultTimeout = 30 * time.Second
+
+// WaitDelay bounds the time spent waiting for the output pipes once a command is cancelled,
+// a process leaving the group of the command can keep them open.
+const WaitDelay = 2 * time.Second
+
+type TimeoutError struct {
+	Timeout time.Duration
+	// LogPath is the path of the log of the invocation, if it was written
+	LogPath string
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %ds", int(e.Timeout.Seconds()))
+}
+
+type CommandError struct {
+	Stderr string
+	// LogPath is the path of the log of the invocation, if it was written
+	LogPath string
+}
+
+func (e CommandError) Error() string {
+	return fmt.Sprintf("command failed: %s", e.Stderr)
 }
 
 type Preferences map[string]any
@@ -60,6 +92,24 @@ func (e Extension) Command(name string) (sunbeam.CommandSpec, bool) {
 	return sunbeam.CommandSpec{}, false
 }
 
+// Timeout returns the maximum duration a command is allowed to run.
+// The config takes precedence over the command, which takes precedence over the manifest.
+func (e Extension) Timeout(name string) time.Duration {
+	if e.Config.Timeout > 0 {
+		return time.Duration(e.Config.Timeout) * time.Second
+	}
+
+	if command, ok := e.Command(name); ok && command.Timeout > 0 {
+		return time.Duration(command.Timeout) * time.Second
+	}
+
+	if e.Manifest.Timeout > 0 {
+		return time.Duration(e.Manifest.Timeout) * time.Second
+	}
+
+	return DefaultTimeout
+}
+
 func (e Extension) RootCommands() []sunbeam.CommandSpec {
 	rootCommands := make([]sunbeam.CommandSpec, 0)
 	for _, command := range e.Manifest.Commands {
@@ -79,16 +129,40 @@ func (e Extension) Run(input sunbeam.Payload) error {
 }
 
 func (ext Extension) Output(input sunbeam.Payload) ([]byte, error) {
-	cmd, err := ext.Cmd(input)
+	return ext.OutputContext(context.Background(), input)
+}
+
+func (ext Extension) OutputContext(ctx context.Context, input sunbeam.Payload) ([]byte, error) {
+	timeout := ext.Timeout(input.Command)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd, err := ext.CmdContext(ctx, input)
 	if err != nil {
 		return nil, err
 	}
+	SetProcessGroup(cmd)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = TimeoutError{Timeout: timeout}
+	}
+
+	// logging is best effort, it should never make the command fail
+	logPath, _ := ext.WriteLog(input, stderr.Bytes(), err)
 
 	var exitErr *exec.ExitError
-	if output, err := cmd.Output(); err == nil {
+	var timeoutErr TimeoutError
+	if err == nil {
 		return output, nil
 	} else if errors.As(err, &exitErr) {
-		return nil, fmt.Errorf("command failed: %s", stripansi.Strip(string(exitErr.Stderr)))
+		return nil, CommandError{Stderr: stripansi.Strip(stderr.String()), LogPath: logPath}
+	} else if errors.As(err, &timeoutErr) {
+		timeoutErr.LogPath = logPath
+		return nil, timeoutErr
 	} else {
 		return nil, err
 	}
@@ -99,9 +173,26 @@ func (e Extension) Cmd(input sunbeam.Payload) (*exec.Cmd, error) {
 }
 
 func (e Extension) CmdContext(ctx context.Context, input sunbeam.Payload) (*exec.Cmd, error) {
-	if input.Preferences == nil {
-		input.Preferences = make(map[string]any)
+	// the commands referenced by the preferences share the timeout of the invocation,
+	// the ones of the tty commands are bounded by the timeout of the command instead of blocking forever
+	substituteCtx := ctx
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		substituteCtx, cancel = context.WithTimeout(ctx, e.Timeout(input.Command))
+		defer cancel()
+	}
+
+	// the preferences are copied, so that the resolved references never end up in the config
+	preferences := make(map[string]any, len(input.Preferences))
+	for name, value := range input.Preferences {
+		resolved, err := e.Config.Substitute(substituteCtx, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve preference %s: %w", name, err)
+		}
+
+		preferences[name] = resolved
 	}
+	input.Preferences = preferences
 
 	for _, spec := range e.Manifest.Preferences {
 		if _, ok := input.Preferences[spec.Name]; ok {
@@ -200,7 +291,8 @@ func DownloadEntrypoint(origin string, target string) error {
 	return nil
 }
 
-func LoadEntrypoint(origin string, extensionDir string) (string, error) {
+func LoadEntrypoint(extensionConfig config.ExtensionConfig, extensionDir string) (string, error) {
+	origin := extensionConfig.Origin
 	if IsRemote(origin) {
 		originUrl, err := url.Parse(origin)
 		if err != nil {
@@ -227,23 +319,24 @@ func LoadEntrypoint(origin string, extensionDir string) (string, error) {
 		return entrypoint, nil
 	}
 
-	entrypoint := origin
-	if strings.HasPrefix(entrypoint, "~") {
-		entrypoint = strings.Replace(entrypoint, "~", os.Getenv("HOME"), 1)
-	} else if !filepath.IsAbs(entrypoint) {
-		entrypoint = filepath.Join(filepath.Dir(config.Path), entrypoint)
+	return filepath.Abs(extensionConfig.ResolveOrigin())
+}
+
+func extensionDir(origin string) (string, error) {
+	hash, err := Hash(origin)
+	if err != nil {
+		return "", err
 	}
 
-	return filepath.Abs(entrypoint)
+	return filepath.Join(utils.CacheDir(), "extensions", hash), nil
 }
 
-func LoadExtension(origin string) (Extension, error) {
-	hash, err := Hash(origin)
+func LoadExtension(extensionConfig config.ExtensionConfig) (Extension, error) {
+	extensionDir, err := extensionDir(extensionConfig.ResolveOrigin())
 	if err != nil {
 		return Extension{}, err
 	}
-	extensionDir := filepath.Join(utils.CacheDir(), "extensions", hash)
-	entrypoint, err := LoadEntrypoint(origin, extensionDir)
+	entrypoint, err := LoadEntrypoint(extensionConfig, extensionDir)
 	if err != nil {
 		return Extension{}, err
 	}
@@ -256,7 +349,7 @@ func LoadExtension(origin string) (Extension, error) {
 	manifestPath := filepath.Join(extensionDir, "manifest.json")
 	manifestInfo, err := os.Stat(manifestPath)
 	if err != nil || entrypointInfo.ModTime().After(manifestInfo.ModTime()) {
-		manifest, err := cacheManifest(entrypoint, manifestPath)
+		manifest, err := cacheManifest(entrypoint, manifestPath, ManifestTimeout(extensionConfig))
 		if err != nil {
 			return Extension{}, err
 		}
@@ -264,6 +357,7 @@ func LoadExtension(origin string) (Extension, error) {
 		return Extension{
 			Manifest:   manifest,
 			Entrypoint: entrypoint,
+			Config:     extensionConfig,
 		}, nil
 	}
 
@@ -280,11 +374,22 @@ func LoadExtension(origin string) (Extension, error) {
 	return Extension{
 		Manifest:   manifest,
 		Entrypoint: entrypoint,
+		Config:     extensionConfig,
 	}, nil
 }
 
-func cacheManifest(entrypoint string, manifestPath string) (sunbeam.Manifest, error) {
-	manifest, err := ExtractManifest(entrypoint)
+// ManifestTimeout returns the timeout used to extract the manifest.
+// The manifest is not known yet, so only the config can override the default.
+func ManifestTimeout(extensionConfig config.ExtensionConfig) time.Duration {
+	if extensionConfig.Timeout > 0 {
+		return time.Duration(extensionConfig.Timeout) * time.Second
+	}
+
+	return DefaultTimeout
+}
+
+func cacheManifest(entrypoint string, manifestPath string, timeout time.Duration) (sunbeam.Manifest, error) {
+	manifest, err := ExtractManifest(entrypoint, timeout)
 	if err != nil {
 		return sunbeam.Manifest{}, fmt.Errorf("failed to extract manifest: %w", err)
 	}
@@ -307,12 +412,11 @@ func cacheManifest(entrypoint string, manifestPath string) (sunbeam.Manifest, er
 }
 
 func Upgrade(extensionConfig config.ExtensionConfig) error {
-	hash, err := Hash(extensionConfig.Origin)
+	extensionDir, err := extensionDir(extensionConfig.ResolveOrigin())
 	if err != nil {
 		return err
 	}
 
-	extensionDir := filepath.Join(utils.CacheDir(), "extensions", hash)
 	manifestPath := filepath.Join(extensionDir, "manifest.json")
 	if IsRemote(extensionConfig.Origin) {
 		originUrl, err := url.Parse(extensionConfig.Origin)
@@ -325,27 +429,24 @@ func Upgrade(extensionConfig config.ExtensionConfig) error {
 			return err
 		}
 
-		if _, err := cacheManifest(entrypoint, manifestPath); err != nil {
+		if _, err := cacheManifest(entrypoint, manifestPath, ManifestTimeout(extensionConfig)); err != nil {
 			return err
 		}
 
 		return nil
 	}
 
-	entrypoint := extensionConfig.Origin
-	if strings.HasPrefix(entrypoint, "~") {
-		entrypoint = strings.Replace(entrypoint, "~", os.Getenv("HOME"), 1)
-	} else if !filepath.IsAbs(entrypoint) {
-		entrypoint = filepath.Join(filepath.Dir(config.Path), entrypoint)
-	}
-
-	if _, err := cacheManifest(entrypoint, manifestPath); err != nil {
+	entrypoint := extensionConfig.ResolveOrigin()
+	if _, err := cacheManifest(entrypoint, manifestPath, ManifestTimeout(extensionConfig)); err != nil {
 		return err
 	}
 	return nil
 }
 
-func ExtractManifest(entrypoint string) (sunbeam.Manifest, error) {
+func ExtractManifest(entrypoint string, timeout time.Duration) (sunbeam.Manifest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
 	entrypoint, err := filepath.Abs(entrypoint)
 	if err != nil {
 		return sunbeam.Manifest{}, err
@@ -355,13 +456,18 @@ func ExtractManifest(entrypoint string) (sunbeam.Manifest, error) {
 		return sunbeam.Manifest{}, err
 	}
 
-	cmd := exec.Command(entrypoint)
+	cmd := exec.CommandContext(ctx, entrypoint)
 	cmd.Dir = filepath.Dir(entrypoint)
 	cmd.Env = os.Environ()
 	cmd.Env = append(cmd.Env, "SUNBEAM=1")
+	SetProcessGroup(cmd)
 
 	manifestBytes, err := cmd.Output()
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return sunbeam.Manifest{}, TimeoutError{Timeout: timeout}
+		}
+
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			return sunbeam.Manifest{}, fmt.Errorf("command failed: %s", stripansi.Strip(string(exitErr.Stderr)))
 		}
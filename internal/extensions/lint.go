@@ -0,0 +1,194 @@
+package extensions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+)
+
+// Warning is a semantic issue found in a manifest or in a command output.
+// The location is a json pointer to the faulty value.
+type Warning struct {
+	Location string
+	Message  string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Location, w.Message)
+}
+
+var envNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// PreferenceEnv returns the name of the environment variable used to override a preference.
+func PreferenceEnv(alias string, name string) string {
+	env := fmt.Sprintf("%s_%s", strings.ToUpper(alias), strings.ToUpper(name))
+	return strings.ReplaceAll(env, "-", "_")
+}
+
+// LintManifest performs the checks that the manifest schema cannot express.
+// References are the names of the commands used by run actions outside of the manifest.
+func LintManifest(manifest sunbeam.Manifest, references map[string]bool) []Warning {
+	var warnings []Warning
+
+	envs := make(map[string]string)
+	for i, preference := range manifest.Preferences {
+		location := fmt.Sprintf("/preferences/%d/name", i)
+		if !envNameRegexp.MatchString(preference.Name) {
+			warnings = append(warnings, Warning{location, fmt.Sprintf("preference %s cannot be mapped to an environment variable", preference.Name)})
+			continue
+		}
+
+		env := PreferenceEnv("", preference.Name)
+		if other, ok := envs[env]; ok {
+			warnings = append(warnings, Warning{location, fmt.Sprintf("preference %s maps to the same environment variable as %s", preference.Name, other)})
+			continue
+		}
+		envs[env] = preference.Name
+	}
+	warnings = append(warnings, lintInputs(manifest.Preferences, "/preferences")...)
+
+	seen := make(map[string]bool)
+	for i, command := range manifest.Commands {
+		location := fmt.Sprintf("/commands/%d", i)
+		if seen[command.Name] {
+			warnings = append(warnings, Warning{location + "/name", fmt.Sprintf("duplicate command name %s", command.Name)})
+		}
+		seen[command.Name] = true
+
+		warnings = append(warnings, lintInputs(command.Params, location+"/params")...)
+
+		if !command.Hidden || references[command.Name] {
+			continue
+		}
+
+		for _, param := range command.Params {
+			if param.Optional {
+				continue
+			}
+
+			warnings = append(warnings, Warning{location, fmt.Sprintf("hidden command %s has required params but is never referenced", command.Name)})
+			break
+		}
+	}
+
+	return warnings
+}
+
+func lintInputs(inputs []sunbeam.Input, location string) []Warning {
+	var warnings []Warning
+
+	seen := make(map[string]bool)
+	for i, input := range inputs {
+		if seen[input.Name] {
+			warnings = append(warnings, Warning{fmt.Sprintf("%s/%d/name", location, i), fmt.Sprintf("duplicate name %s", input.Name)})
+		}
+		seen[input.Name] = true
+
+		if input.Default == nil {
+			continue
+		}
+
+		var valid bool
+		switch input.Type {
+		case sunbeam.InputString:
+			_, valid = input.Default.(string)
+		case sunbeam.InputBoolean:
+			_, valid = input.Default.(bool)
+		case sunbeam.InputNumber:
+			_, valid = input.Default.(float64)
+		}
+
+		if !valid {
+			warnings = append(warnings, Warning{fmt.Sprintf("%s/%d/default", location, i), fmt.Sprintf("default value does not match type %s", input.Type)})
+		}
+	}
+
+	return warnings
+}
+
+// RunReferences returns the commands of the current extension referenced by the run actions.
+func RunReferences(actions []sunbeam.Action) map[string]bool {
+	references := make(map[string]bool)
+	for _, action := range actions {
+		if action.Type != sunbeam.ActionTypeRun || action.Run == nil || action.Run.Extension != "" {
+			continue
+		}
+
+		references[action.Run.Command] = true
+	}
+
+	return references
+}
+
+// ListActions returns all the actions of a list, including the item actions.
+func ListActions(list sunbeam.List) []sunbeam.Action {
+	actions := append([]sunbeam.Action{}, list.Actions...)
+	for _, item := range list.Items {
+		actions = append(actions, item.Actions...)
+	}
+
+	return actions
+}
+
+// LintActions checks that the run actions point to existing commands and only use declared params.
+func LintActions(manifest sunbeam.Manifest, actions []sunbeam.Action, location string) []Warning {
+	var warnings []Warning
+
+	extension := Extension{Manifest: manifest}
+	for i, action := range actions {
+		if action.Type != sunbeam.ActionTypeRun || action.Run == nil || action.Run.Extension != "" {
+			continue
+		}
+
+		actionLocation := fmt.Sprintf("%s/%d", location, i)
+		command, ok := extension.Command(action.Run.Command)
+		if !ok {
+			warnings = append(warnings, Warning{actionLocation + "/command", fmt.Sprintf("run action points to unknown command %s", action.Run.Command)})
+			continue
+		}
+
+		for name := range action.Run.Params {
+			var declared bool
+			for _, param := range command.Params {
+				if param.Name == name {
+					declared = true
+					break
+				}
+			}
+
+			if !declared {
+				warnings = append(warnings, Warning{fmt.Sprintf("%s/params/%s", actionLocation, name), fmt.Sprintf("param %s is not declared by command %s", name, command.Name)})
+			}
+		}
+	}
+
+	return warnings
+}
+
+// LintList checks the actions of a list and looks for duplicate item ids.
+func LintList(manifest sunbeam.Manifest, list sunbeam.List, location string) []Warning {
+	var warnings []Warning
+	warnings = append(warnings, LintActions(manifest, list.Actions, location+"/actions")...)
+
+	ids := make(map[string]bool)
+	for i, item := range list.Items {
+		itemLocation := fmt.Sprintf("%s/items/%d", location, i)
+		if item.Id != "" {
+			if ids[item.Id] {
+				warnings = append(warnings, Warning{itemLocation + "/id", fmt.Sprintf("duplicate item id %s", item.Id)})
+			}
+			ids[item.Id] = true
+		}
+
+		warnings = append(warnings, LintActions(manifest, item.Actions, itemLocation+"/actions")...)
+	}
+
+	return warnings
+}
+
+// LintDetail checks the actions of a detail.
+func LintDetail(manifest sunbeam.Manifest, detail sunbeam.Detail, location string) []Warning {
+	return LintActions(manifest, detail.Actions, location+"/actions")
+}
@@ -0,0 +1,183 @@
+package extensions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+)
+
+func TestLintManifest(t *testing.T) {
+	testCases := []struct {
+		name       string
+		manifest   sunbeam.Manifest
+		references map[string]bool
+		warnings   []Warning
+	}{
+		{
+			name: "valid manifest",
+			manifest: sunbeam.Manifest{
+				Preferences: []sunbeam.Input{{Name: "token", Type: sunbeam.InputString, Default: "secret"}},
+				Commands: []sunbeam.CommandSpec{
+					{Name: "list"},
+					{Name: "show", Hidden: true, Params: []sunbeam.Input{{Name: "id", Type: sunbeam.InputString}}},
+				},
+			},
+			references: map[string]bool{"show": true},
+		},
+		{
+			name: "duplicate commands",
+			manifest: sunbeam.Manifest{
+				Commands: []sunbeam.CommandSpec{{Name: "list"}, {Name: "show"}, {Name: "list"}},
+			},
+			warnings: []Warning{{"/commands/2/name", "duplicate command name list"}},
+		},
+		{
+			name: "unreachable hidden command",
+			manifest: sunbeam.Manifest{
+				Commands: []sunbeam.CommandSpec{{Name: "show", Hidden: true, Params: []sunbeam.Input{{Name: "id", Type: sunbeam.InputString}}}},
+			},
+			warnings: []Warning{{"/commands/0", "hidden command show has required params but is never referenced"}},
+		},
+		{
+			name: "hidden command with optional params",
+			manifest: sunbeam.Manifest{
+				Commands: []sunbeam.CommandSpec{{Name: "show", Hidden: true, Params: []sunbeam.Input{{Name: "id", Type: sunbeam.InputString, Optional: true}}}},
+			},
+		},
+		{
+			name: "invalid env name",
+			manifest: sunbeam.Manifest{
+				Preferences: []sunbeam.Input{{Name: "api token", Type: sunbeam.InputString}},
+			},
+			warnings: []Warning{{"/preferences/0/name", "preference api token cannot be mapped to an environment variable"}},
+		},
+		{
+			name: "env name clash",
+			manifest: sunbeam.Manifest{
+				Preferences: []sunbeam.Input{{Name: "api-token", Type: sunbeam.InputString}, {Name: "API_TOKEN", Type: sunbeam.InputString}},
+			},
+			warnings: []Warning{{"/preferences/1/name", "preference API_TOKEN maps to the same environment variable as api-token"}},
+		},
+		{
+			name: "duplicate params",
+			manifest: sunbeam.Manifest{
+				Commands: []sunbeam.CommandSpec{{Name: "show", Params: []sunbeam.Input{{Name: "id", Type: sunbeam.InputString}, {Name: "id", Type: sunbeam.InputString}}}},
+			},
+			warnings: []Warning{{"/commands/0/params/1/name", "duplicate name id"}},
+		},
+		{
+			name: "default type mismatch",
+			manifest: sunbeam.Manifest{
+				Preferences: []sunbeam.Input{
+					{Name: "verbose", Type: sunbeam.InputBoolean, Default: "true"},
+					{Name: "limit", Type: sunbeam.InputNumber, Default: 10.0},
+				},
+				Commands: []sunbeam.CommandSpec{{Name: "show", Params: []sunbeam.Input{{Name: "id", Type: sunbeam.InputString, Default: 1.0, Optional: true}}}},
+			},
+			warnings: []Warning{
+				{"/preferences/0/default", "default value does not match type boolean"},
+				{"/commands/0/params/0/default", "default value does not match type string"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if warnings := LintManifest(tc.manifest, tc.references); !reflect.DeepEqual(warnings, tc.warnings) {
+				t.Fatalf("expected %v, got %v", tc.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestLintActions(t *testing.T) {
+	manifest := sunbeam.Manifest{
+		Commands: []sunbeam.CommandSpec{{Name: "show", Params: []sunbeam.Input{{Name: "id", Type: sunbeam.InputString}}}},
+	}
+
+	testCases := []struct {
+		name     string
+		actions  []sunbeam.Action
+		warnings []Warning
+	}{
+		{
+			name: "valid run action",
+			actions: []sunbeam.Action{
+				{Type: sunbeam.ActionTypeRun, Run: &sunbeam.RunAction{Command: "show", Params: map[string]any{"id": "1"}}},
+			},
+		},
+		{
+			name: "unknown run target",
+			actions: []sunbeam.Action{
+				{Type: sunbeam.ActionTypeCopy, Copy: &sunbeam.CopyAction{Text: "hello"}},
+				{Type: sunbeam.ActionTypeRun, Run: &sunbeam.RunAction{Command: "hide"}},
+			},
+			warnings: []Warning{{"/actions/1/command", "run action points to unknown command hide"}},
+		},
+		{
+			name: "undeclared param",
+			actions: []sunbeam.Action{
+				{Type: sunbeam.ActionTypeRun, Run: &sunbeam.RunAction{Command: "show", Params: map[string]any{"name": "1"}}},
+			},
+			warnings: []Warning{{"/actions/0/params/name", "param name is not declared by command show"}},
+		},
+		{
+			name: "run action of another extension",
+			actions: []sunbeam.Action{
+				{Type: sunbeam.ActionTypeRun, Run: &sunbeam.RunAction{Extension: "other", Command: "hide"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if warnings := LintActions(manifest, tc.actions, "/actions"); !reflect.DeepEqual(warnings, tc.warnings) {
+				t.Fatalf("expected %v, got %v", tc.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestLintList(t *testing.T) {
+	manifest := sunbeam.Manifest{
+		Commands: []sunbeam.CommandSpec{{Name: "show"}},
+	}
+
+	testCases := []struct {
+		name     string
+		list     sunbeam.List
+		warnings []Warning
+	}{
+		{
+			name: "unique item ids",
+			list: sunbeam.List{Items: []sunbeam.ListItem{{Id: "a"}, {Id: "b"}, {}, {}}},
+		},
+		{
+			name:     "duplicate item ids",
+			list:     sunbeam.List{Items: []sunbeam.ListItem{{Id: "a"}, {Id: "b"}, {Id: "a"}}},
+			warnings: []Warning{{"/items/2/id", "duplicate item id a"}},
+		},
+		{
+			name: "item actions",
+			list: sunbeam.List{
+				Actions: []sunbeam.Action{{Type: sunbeam.ActionTypeRun, Run: &sunbeam.RunAction{Command: "hide"}}},
+				Items: []sunbeam.ListItem{
+					{Id: "a", Actions: []sunbeam.Action{{Type: sunbeam.ActionTypeRun, Run: &sunbeam.RunAction{Command: "list"}}}},
+				},
+			},
+			warnings: []Warning{
+				{"/actions/0/command", "run action points to unknown command hide"},
+				{"/items/0/actions/0/command", "run action points to unknown command list"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if warnings := LintList(manifest, tc.list, ""); !reflect.DeepEqual(warnings, tc.warnings) {
+				t.Fatalf("expected %v, got %v", tc.warnings, warnings)
+			}
+		})
+	}
+}
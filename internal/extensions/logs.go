@@ -0,0 +1,178 @@
+package extensions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/acarl005/stripansi"
+	"github.com/pomdtr/sunbeam/internal/utils"
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+)
+
+// MaxLogSize is the size after which the log file is rotated.
+const MaxLogSize = 512 * 1024
+
+// LogPath returns the path of the file where the stderr of every invocation is appended.
+func LogPath(origin string) (string, error) {
+	dir, err := extensionDir(origin)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "stderr.log"), nil
+}
+
+// MaxRunLogs is the number of failed invocations whose log is kept.
+const MaxRunLogs = 10
+
+// runLogDir returns the directory containing the payload and stderr of the failed invocations, one file per run.
+func runLogDir(origin string) (string, error) {
+	dir, err := extensionDir(origin)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "runs"), nil
+}
+
+func formatLogEntry(input sunbeam.Payload, stderr []byte, runErr error) (string, error) {
+	// preferences often contain secrets, only keep their names
+	preferences := make(map[string]any)
+	for name := range input.Preferences {
+		preferences[name] = "***"
+	}
+	input.Preferences = preferences
+
+	if input.Cwd == "" {
+		if cwd, err := os.Getwd(); err == nil {
+			input.Cwd = cwd
+		}
+	}
+
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+
+	status := "success"
+	if runErr != nil {
+		status = runErr.Error()
+	}
+
+	var entry strings.Builder
+	entry.WriteString(fmt.Sprintf("--- %s %s (%s)\n", time.Now().Format(time.RFC3339), input.Command, status))
+	entry.WriteString(fmt.Sprintf("payload: %s\n", payload))
+	if len(stderr) > 0 {
+		entry.WriteString(stripansi.Strip(string(stderr)))
+		if !strings.HasSuffix(entry.String(), "\n") {
+			entry.WriteString("\n")
+		}
+	}
+
+	return entry.String(), nil
+}
+
+// WriteLog records the stderr of an invocation in the extension logs.
+// The log of a failed invocation is also written to its own file, whose path is returned.
+func (e Extension) WriteLog(input sunbeam.Payload, stderr []byte, runErr error) (string, error) {
+	if e.DisableLogs {
+		return "", nil
+	}
+
+	logPath, err := LogPath(e.Config.ResolveOrigin())
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := formatLogEntry(input, stderr, runErr)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return "", err
+	}
+
+	if err := appendLog(logPath, entry); err != nil {
+		return "", err
+	}
+
+	if runErr == nil {
+		return "", nil
+	}
+
+	return writeRunLog(e.Config.ResolveOrigin(), entry)
+}
+
+// LogFailure records a failed invocation whose stderr was written to the terminal, like the tty commands.
+// The returned error links to the log of the run, which only contains its payload and exit status.
+func (e Extension) LogFailure(input sunbeam.Payload, runErr error) error {
+	// logging is best effort, it should never hide the error of the command
+	logPath, _ := e.WriteLog(input, nil, runErr)
+	return CommandError{Stderr: runErr.Error(), LogPath: logPath}
+}
+
+// appendLog appends the entry to the log file, the lock prevents concurrent invocations from
+// rotating the file twice and losing the previous logs.
+func appendLog(logPath string, entry string) error {
+	unlock, err := utils.LockFile(logPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if info, err := os.Stat(logPath); err == nil && info.Size() > MaxLogSize {
+		if err := os.Rename(logPath, logPath+".1"); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(entry); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func writeRunLog(origin string, entry string) (string, error) {
+	dir, err := runLogDir(origin)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	// the timestamp prefix keeps the files sorted by date
+	f, err := os.CreateTemp(dir, fmt.Sprintf("%d-*.log", time.Now().UnixNano()))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(entry); err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for i := 0; i < len(entries)-MaxRunLogs; i++ {
+		os.Remove(filepath.Join(dir, entries[i].Name()))
+	}
+
+	return f.Name(), nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package extensions
+
+import "os/exec"
+
+func SetProcessGroup(cmd *exec.Cmd) {
+	cmd.WaitDelay = WaitDelay
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package extensions
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// SetProcessGroup runs the command in its own process group, so that
+// cancelling the command also kills the processes spawned by the entrypoint.
+func SetProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = WaitDelay
+}
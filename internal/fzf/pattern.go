@@ -0,0 +1,244 @@
+package fzf
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/junegunn/fzf/src/algo"
+	"github.com/junegunn/fzf/src/util"
+)
+
+type termType int
+
+const (
+	termFuzzy termType = iota
+	termExact
+	termPrefix
+	termSuffix
+	termEqual
+)
+
+type term struct {
+	typ           termType
+	inverse       bool
+	text          []rune
+	caseSensitive bool
+}
+
+func (t term) algo() algo.Algo {
+	switch t.typ {
+	case termExact:
+		return algo.ExactMatchNaive
+	case termPrefix:
+		return algo.PrefixMatch
+	case termSuffix:
+		return algo.SuffixMatch
+	case termEqual:
+		return algo.EqualMatch
+	default:
+		return algo.FuzzyMatchV1
+	}
+}
+
+// Pattern is a query using the extended search syntax of fzf.
+// The terms are separated by spaces and must all match, terms separated by a `|` match if any of them does.
+// A term prefixed with `'` is an exact match, `^` a prefix match, `$` a suffix match, and `!` negates the term.
+type Pattern struct {
+	// groups of terms, an input matches the pattern if at least one term of each group matches
+	groups [][]term
+}
+
+func ParsePattern(query string) Pattern {
+	var pattern Pattern
+	var group []term
+	continued := false
+
+	for _, token := range tokenize(query) {
+		if token == "|" {
+			continued = len(group) > 0
+			continue
+		}
+
+		t, ok := parseTerm(token)
+		if !ok {
+			continue
+		}
+
+		if !continued && len(group) > 0 {
+			pattern.groups = append(pattern.groups, group)
+			group = nil
+		}
+
+		group = append(group, t)
+		continued = false
+	}
+
+	if len(group) > 0 {
+		pattern.groups = append(pattern.groups, group)
+	}
+
+	return pattern
+}
+
+// tokenize splits the query on spaces, a space escaped by a backslash is part of the token.
+func tokenize(query string) []string {
+	var tokens []string
+	var token strings.Builder
+	for i := 0; i < len(query); i++ {
+		switch {
+		case query[i] == '\\' && i+1 < len(query) && query[i+1] == ' ':
+			token.WriteByte(' ')
+			i++
+		case query[i] == ' ':
+			if token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+		default:
+			token.WriteByte(query[i])
+		}
+	}
+
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+
+	return tokens
+}
+
+func parseTerm(token string) (term, bool) {
+	t := term{typ: termFuzzy}
+
+	if strings.HasPrefix(token, "!") {
+		// negated terms are exact matches, unless they are explicitly fuzzy
+		t.inverse = true
+		t.typ = termExact
+		token = token[1:]
+	}
+
+	switch {
+	case strings.HasPrefix(token, "'"):
+		if t.inverse {
+			t.typ = termFuzzy
+		} else {
+			t.typ = termExact
+		}
+		token = token[1:]
+	case strings.HasPrefix(token, "^") && strings.HasSuffix(token, "$") && len(token) > 1:
+		t.typ = termEqual
+		token = token[1 : len(token)-1]
+	case strings.HasPrefix(token, "^"):
+		t.typ = termPrefix
+		token = token[1:]
+	case strings.HasSuffix(token, "$"):
+		t.typ = termSuffix
+		token = token[:len(token)-1]
+	}
+
+	if token == "" {
+		return term{}, false
+	}
+
+	// follow the out of the box logic of the fzf CLI, making the search "smart-case" sensitive
+	// if there is an upper case letter the search is case sensitive, otherwise it is not
+	t.caseSensitive = !IsLower(token)
+	t.text = []rune(token)
+	return t, true
+}
+
+// Empty reports whether the pattern has no terms, in which case every input matches.
+func (p Pattern) Empty() bool {
+	return len(p.groups) == 0
+}
+
+// Text is an input converted to the representation used by the fzf algorithms.
+// Converting the inputs once allows to match them against several patterns without allocating.
+type Text struct {
+	chars util.Chars
+}
+
+func NewText(input string) *Text {
+	return &Text{chars: util.ToChars([]byte(input))}
+}
+
+// Match returns whether the input matches the pattern, along with its score and the positions
+// of the matched runes in ascending order.
+func (p Pattern) Match(input string) (bool, int, []int) {
+	return p.MatchText(NewText(input), true)
+}
+
+// MatchText is the same as Match for a converted input, the positions are only computed when withPos is set.
+func (p Pattern) MatchText(text *Text, withPos bool) (bool, int, []int) {
+	var score int
+	var positions []int
+	for _, group := range p.groups {
+		matched := false
+		for _, t := range group {
+			res, pos := t.algo()(t.caseSensitive, false, true, &text.chars, t.text, withPos && !t.inverse, nil)
+			found := res.Start >= 0
+
+			// a negated term matches when its text is not found
+			if t.inverse {
+				if !found {
+					matched = true
+					break
+				}
+				continue
+			}
+
+			if !found {
+				continue
+			}
+
+			matched = true
+			score += res.Score
+			if !withPos {
+				break
+			}
+
+			if pos != nil {
+				positions = append(positions, *pos...)
+			} else {
+				for i := res.Start; i < res.End; i++ {
+					positions = append(positions, i)
+				}
+			}
+			break
+		}
+
+		if !matched {
+			return false, 0, nil
+		}
+	}
+
+	if !withPos {
+		return true, score, nil
+	}
+
+	return true, score, unique(positions)
+}
+
+// Narrows reports whether every input matching the query also matches the previous one,
+// in which case only the matches of the previous query need to be searched.
+// Queries adding an alternative, a negation or an escape can match new inputs, as can
+// queries extending a suffix term.
+func Narrows(previous, query string) bool {
+	if previous == "" || !strings.HasPrefix(query, previous) {
+		return false
+	}
+
+	return !strings.ContainsAny(query, "|!\\") && !strings.Contains(previous, "$")
+}
+
+// unique sorts the positions and removes the duplicates, as several terms can match the same runes.
+func unique(positions []int) []int {
+	sort.Ints(positions)
+	res := positions[:0]
+	for i, pos := range positions {
+		if i == 0 || pos != positions[i-1] {
+			res = append(res, pos)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,141 @@
+package fzf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	testCases := []struct {
+		query  string
+		groups [][]term
+	}{
+		{query: "", groups: nil},
+		{query: "   ", groups: nil},
+		{query: "abc", groups: [][]term{{{typ: termFuzzy, text: []rune("abc")}}}},
+		{query: "'abc", groups: [][]term{{{typ: termExact, text: []rune("abc")}}}},
+		{query: "^abc", groups: [][]term{{{typ: termPrefix, text: []rune("abc")}}}},
+		{query: "abc$", groups: [][]term{{{typ: termSuffix, text: []rune("abc")}}}},
+		{query: "^abc$", groups: [][]term{{{typ: termEqual, text: []rune("abc")}}}},
+		{query: "!abc", groups: [][]term{{{typ: termExact, inverse: true, text: []rune("abc")}}}},
+		{query: "!'abc", groups: [][]term{{{typ: termFuzzy, inverse: true, text: []rune("abc")}}}},
+		{query: "!^abc", groups: [][]term{{{typ: termPrefix, inverse: true, text: []rune("abc")}}}},
+		{query: "Abc", groups: [][]term{{{typ: termFuzzy, text: []rune("Abc"), caseSensitive: true}}}},
+		{query: "a b", groups: [][]term{
+			{{typ: termFuzzy, text: []rune("a")}},
+			{{typ: termFuzzy, text: []rune("b")}},
+		}},
+		{query: "a | ^b c", groups: [][]term{
+			{{typ: termFuzzy, text: []rune("a")}, {typ: termPrefix, text: []rune("b")}},
+			{{typ: termFuzzy, text: []rune("c")}},
+		}},
+		{query: "| a |", groups: [][]term{{{typ: termFuzzy, text: []rune("a")}}}},
+		{query: `'a\ b`, groups: [][]term{{{typ: termExact, text: []rune("a b")}}}},
+		{query: "a\tb", groups: [][]term{{{typ: termFuzzy, text: []rune("a\tb")}}}},
+		{query: `a\b`, groups: [][]term{{{typ: termFuzzy, text: []rune(`a\b`)}}}},
+		{query: "' ^ $ !", groups: nil},
+	}
+
+	for _, tc := range testCases {
+		if pattern := ParsePattern(tc.query); !reflect.DeepEqual(pattern.groups, tc.groups) {
+			t.Errorf("ParsePattern(%q): expected %+v, got %+v", tc.query, tc.groups, pattern.groups)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		query   string
+		input   string
+		matched bool
+	}{
+		{query: "", input: "anything", matched: true},
+		{query: "sbtrkt", input: "subtract kit", matched: true},
+		{query: "sbtrkt", input: "subtract", matched: false},
+		{query: "abc", input: "ABC", matched: true},
+		{query: "Abc", input: "abc", matched: false},
+		{query: "'bra", input: "abracadabra", matched: true},
+		{query: "'brd", input: "abracadabra", matched: false},
+		{query: "^abr", input: "abracadabra", matched: true},
+		{query: "^bra", input: "abracadabra", matched: false},
+		{query: "bra$", input: "abracadabra", matched: true},
+		{query: "abr$", input: "abracadabra", matched: false},
+		{query: "^abc$", input: "abc", matched: true},
+		{query: "^abc$", input: "abcd", matched: false},
+		{query: "!fire", input: "water", matched: true},
+		{query: "!fire", input: "campfire", matched: false},
+		{query: "!fre", input: "campfire", matched: true},
+		{query: "!'fre", input: "campfire", matched: false},
+		{query: "!^camp", input: "campfire", matched: false},
+		{query: "!.mp3$", input: "song.mp3", matched: false},
+		{query: "!.mp3$", input: "song.ogg", matched: true},
+		{query: "camp fire", input: "campfire", matched: true},
+		{query: "camp water", input: "campfire", matched: false},
+		{query: "water | fire", input: "campfire", matched: true},
+		{query: "water | earth", input: "campfire", matched: false},
+		{query: "^water | !camp", input: "campfire", matched: false},
+		{query: `'camp\ fire`, input: "camp fire", matched: true},
+		{query: `'camp\ fire`, input: "campfire", matched: false},
+		{query: "'camp\tfire", input: "camp\tfire", matched: true},
+		{query: "'camp\tfire", input: "camp fire", matched: false},
+	}
+
+	for _, tc := range testCases {
+		if matched, _, _ := ParsePattern(tc.query).Match(tc.input); matched != tc.matched {
+			t.Errorf("%q matching %q: expected %v, got %v", tc.query, tc.input, tc.matched, matched)
+		}
+	}
+}
+
+func TestMatchPositions(t *testing.T) {
+	testCases := []struct {
+		query     string
+		input     string
+		positions []int
+	}{
+		{query: "ace", input: "abcde", positions: []int{0, 2, 4}},
+		{query: "'cd", input: "abcde", positions: []int{2, 3}},
+		{query: "^ab de$", input: "abcde", positions: []int{0, 1, 3, 4}},
+		{query: "'bc 'cd", input: "abcde", positions: []int{1, 2, 3}},
+		{query: "!x 'e", input: "abcde", positions: []int{4}},
+	}
+
+	for _, tc := range testCases {
+		text := NewText(tc.input)
+		matched, _, positions := ParsePattern(tc.query).MatchText(text, true)
+		if !matched || !reflect.DeepEqual(positions, tc.positions) {
+			t.Errorf("%q matching %q: expected positions %v, got %v", tc.query, tc.input, tc.positions, positions)
+		}
+
+		if _, _, positions := ParsePattern(tc.query).MatchText(text, false); positions != nil {
+			t.Errorf("%q matching %q: expected no positions, got %v", tc.query, tc.input, positions)
+		}
+	}
+}
+
+func TestNarrows(t *testing.T) {
+	testCases := []struct {
+		previous string
+		query    string
+		narrows  bool
+	}{
+		{previous: "", query: "a", narrows: false},
+		{previous: "a", query: "ab", narrows: true},
+		{previous: "ab", query: "a", narrows: false},
+		{previous: "a", query: "b", narrows: false},
+		{previous: "a", query: "a b", narrows: true},
+		{previous: "'a", query: "'ab", narrows: true},
+		{previous: "^a", query: "^ab", narrows: true},
+		{previous: "a", query: "a | b", narrows: false},
+		{previous: "a", query: "a !b", narrows: false},
+		{previous: "!a", query: "!ab", narrows: false},
+		{previous: "a$", query: "a$b", narrows: false},
+		{previous: "a", query: `a\ b`, narrows: false},
+	}
+
+	for _, tc := range testCases {
+		if narrows := Narrows(tc.previous, tc.query); narrows != tc.narrows {
+			t.Errorf("Narrows(%q, %q): expected %v, got %v", tc.previous, tc.query, tc.narrows, narrows)
+		}
+	}
+}
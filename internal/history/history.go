@@ -2,70 +2,348 @@ package history
 
 import (
 	"encoding/json"
+	"maps"
 	"os"
 	"path/filepath"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/pomdtr/sunbeam/internal/utils"
-	"github.com/pomdtr/sunbeam/pkg/sunbeam"
 )
 
 var Path = filepath.Join(utils.CacheDir(), "history.json")
 
+const (
+	version = 2
+	// maximum number of queries remembered for each entry
+	maxQueries = 20
+)
+
+// Store is the history subsystem used to rank the items the user picks.
+type Store interface {
+	// Load reads the entries from disk, keeping the changes that were not saved yet.
+	Load() error
+	// Record registers that an item was picked, with the query typed when it was picked.
+	Record(id string, query string)
+	// Rank returns the score of an item for the given query, higher is better.
+	Rank(id string, query string) float64
+	// Save writes the changes to disk.
+	Save() error
+}
+
 type History struct {
-	entries map[string]int64
+	entries map[string]Entry
 	path    string
+	// changes made since the history was loaded, applied on top of the file content when saving
+	updates map[string]Entry
+	removed map[string]bool
+	// entries imported from another history, combined with the file content instead of added to it
+	imported map[string]Entry
+}
+
+var _ Store = History{}
+
+type Entry struct {
+	Count    int            `json:"count"`
+	LastUsed int64          `json:"lastUsed"`
+	Queries  map[string]int `json:"queries,omitempty"`
+}
+
+type historyFile struct {
+	Version int              `json:"version"`
+	Entries map[string]Entry `json:"entries"`
+}
+
+// New returns an empty history backed by the given file, use Load to read its content.
+func New(historyPath string) History {
+	return History{
+		entries:  map[string]Entry{},
+		path:     historyPath,
+		updates:  map[string]Entry{},
+		removed:  map[string]bool{},
+		imported: map[string]Entry{},
+	}
 }
 
 func Load(historyPath string) (History, error) {
-	bts, err := os.ReadFile(historyPath)
-	if os.IsNotExist(err) {
-		return History{
-			entries: map[string]int64{},
-			path:    historyPath,
-		}, nil
-	} else if os.IsNotExist(err) {
+	h := New(historyPath)
+	if err := h.Load(); err != nil {
 		return History{}, err
 	}
 
-	var entries map[string]int64
-	if err := json.Unmarshal(bts, &entries); err != nil {
-		return History{}, err
+	return h, nil
+}
+
+func (h History) Load() error {
+	entries, err := h.read()
+	if err != nil {
+		return err
 	}
 
-	return History{
-		entries: entries,
-		path:    historyPath,
-	}, nil
+	h.apply(entries)
+	h.replace(entries)
+	return nil
 }
 
-func (h History) Sort(items []sunbeam.ListItem) {
-	sort.SliceStable(items, func(i, j int) bool {
-		keyI := items[i].Id
-		keyJ := items[j].Id
+// read returns the entries stored on disk. A corrupted file is moved aside instead of
+// preventing sunbeam from starting, since the history can always be rebuilt.
+func (h History) read() (map[string]Entry, error) {
+	bts, err := os.ReadFile(h.path)
+	if os.IsNotExist(err) {
+		return make(map[string]Entry), nil
+	} else if err != nil {
+		return nil, err
+	}
 
-		return h.entries[keyI] > h.entries[keyJ]
-	})
+	entries, err := Decode(bts)
+	if err != nil {
+		if err := os.Rename(h.path, h.path+".corrupted"); err != nil {
+			return nil, err
+		}
+
+		return make(map[string]Entry), nil
+	}
+
+	return entries, nil
+}
+
+// apply applies the changes that were not saved yet to the entries read from disk.
+func (h History) apply(entries map[string]Entry) {
+	for key := range h.removed {
+		delete(entries, key)
+	}
+	union(entries, h.imported)
+	merge(entries, h.updates)
 }
 
-func (h History) Update(key string) {
-	h.entries[key] = time.Now().Unix()
+func (h History) replace(entries map[string]Entry) {
+	for key := range h.entries {
+		delete(h.entries, key)
+	}
+	for key, entry := range entries {
+		h.entries[key] = entry
+	}
 }
 
+// Decode parses the content of a history file, in the current or in the legacy format.
+func Decode(bts []byte) (map[string]Entry, error) {
+	var file historyFile
+	if err := json.Unmarshal(bts, &file); err == nil && file.Version > 0 {
+		if file.Entries == nil {
+			file.Entries = make(map[string]Entry)
+		}
+		return file.Entries, nil
+	}
+
+	// the first version of the history only stored the last time each item was used
+	var timestamps map[string]int64
+	if err := json.Unmarshal(bts, &timestamps); err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]Entry, len(timestamps))
+	for id, timestamp := range timestamps {
+		entries[id] = Entry{
+			Count:    1,
+			LastUsed: timestamp,
+		}
+	}
+
+	return entries, nil
+}
+
+// Frecency combines the number of times an entry was used with how recently it was used,
+// so that a single accidental use does not outrank an item used every day.
+func (e Entry) Frecency(now time.Time) float64 {
+	age := now.Sub(time.Unix(e.LastUsed, 0))
+
+	var weight float64
+	switch {
+	case age < time.Hour:
+		weight = 4
+	case age < 24*time.Hour:
+		weight = 2
+	case age < 7*24*time.Hour:
+		weight = 1
+	default:
+		weight = 0.5
+	}
+
+	return float64(e.Count) * weight
+}
+
+// Affinity returns how many times the entry was picked with a query related to the given one.
+func (e Entry) Affinity(query string) int {
+	if query == "" {
+		return 0
+	}
+
+	var affinity int
+	for q, count := range e.Queries {
+		if strings.HasPrefix(q, query) || strings.HasPrefix(query, q) {
+			affinity += count
+		}
+	}
+
+	return affinity
+}
+
+// Rank returns the score of an item for the given query, higher is better.
+func (h History) Rank(id string, query string) float64 {
+	entry, ok := h.entries[id]
+	if !ok {
+		return 0
+	}
+
+	return entry.Frecency(time.Now()) + float64(entry.Affinity(query))
+}
+
+// Record registers that an item was picked, with the query typed when it was picked.
+func (h History) Record(key string, query string) {
+	now := time.Now().Unix()
+	for _, entries := range []map[string]Entry{h.entries, h.updates} {
+		entry := entries[key]
+		entry.Count++
+		entry.LastUsed = now
+
+		if query != "" {
+			if entry.Queries == nil {
+				entry.Queries = make(map[string]int)
+			}
+			entry.Queries[query]++
+			entry.trimQueries(query)
+		}
+
+		entries[key] = entry
+	}
+}
+
+// trimQueries forgets the least used queries, keeping the given one.
+func (e Entry) trimQueries(keep string) {
+	for len(e.Queries) > maxQueries {
+		var leastUsed string
+		for q, count := range e.Queries {
+			if q == keep {
+				continue
+			}
+
+			if leastUsed == "" || count < e.Queries[leastUsed] {
+				leastUsed = q
+			}
+		}
+		delete(e.Queries, leastUsed)
+	}
+}
+
+// Entries returns the entries of the history, indexed by item id.
+func (h History) Entries() map[string]Entry {
+	return h.entries
+}
+
+func (h History) Remove(key string) {
+	delete(h.entries, key)
+	delete(h.updates, key)
+	delete(h.imported, key)
+	h.removed[key] = true
+}
+
+// Merge adds the entries to the history. The entries present in both keep the larger count and
+// the latest use, so that importing a history exported from this one does not count the uses twice.
+func (h History) Merge(entries map[string]Entry) {
+	union(h.entries, entries)
+	union(h.imported, entries)
+}
+
+// union is the same as merge for entries counting the same uses, it keeps the larger counts instead of adding them.
+func union(target map[string]Entry, entries map[string]Entry) {
+	for key, entry := range entries {
+		existing, ok := target[key]
+		if !ok {
+			entry.Queries = maps.Clone(entry.Queries)
+			target[key] = entry
+			continue
+		}
+
+		existing.Count = max(existing.Count, entry.Count)
+		existing.LastUsed = max(existing.LastUsed, entry.LastUsed)
+		for query, count := range entry.Queries {
+			if existing.Queries == nil {
+				existing.Queries = make(map[string]int)
+			}
+			existing.Queries[query] = max(existing.Queries[query], count)
+		}
+		existing.trimQueries("")
+
+		target[key] = existing
+	}
+}
+
+// merge adds the uses recorded since the history was loaded to the entries.
+func merge(target map[string]Entry, entries map[string]Entry) {
+	for key, entry := range entries {
+		existing, ok := target[key]
+		if !ok {
+			entry.Queries = maps.Clone(entry.Queries)
+			target[key] = entry
+			continue
+		}
+
+		existing.Count += entry.Count
+		existing.LastUsed = max(existing.LastUsed, entry.LastUsed)
+		for query, count := range entry.Queries {
+			if existing.Queries == nil {
+				existing.Queries = make(map[string]int)
+			}
+			existing.Queries[query] += count
+		}
+		existing.trimQueries("")
+
+		target[key] = existing
+	}
+}
+
+func (h History) MarshalJSON() ([]byte, error) {
+	return json.Marshal(historyFile{
+		Version: version,
+		Entries: h.entries,
+	})
+}
+
+// Save writes the history to disk. The file is read again before writing,
+// so that the entries recorded by other sunbeam processes in the meantime are kept.
 func (h History) Save() error {
-	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+	unlock, err := utils.LockFile(h.path)
+	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	bts, err := json.MarshalIndent(h.entries, "", "  ")
+	entries, err := h.read()
 	if err != nil {
 		return err
 	}
 
-	if err := os.WriteFile(h.path, bts, 0644); err != nil {
+	h.apply(entries)
+	bts, err := json.MarshalIndent(History{entries: entries}, "", "  ")
+	if err != nil {
 		return err
 	}
 
+	if err := utils.WriteFileAtomic(h.path, bts, 0644); err != nil {
+		return err
+	}
+
+	// the changes are now part of the file
+	h.replace(entries)
+	for key := range h.updates {
+		delete(h.updates, key)
+	}
+	for key := range h.removed {
+		delete(h.removed, key)
+	}
+	for key := range h.imported {
+		delete(h.imported, key)
+	}
+
 	return nil
 }
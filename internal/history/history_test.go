@@ -0,0 +1,206 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "history.json")
+
+	h, err := Load(historyPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(h.Entries()) != 0 {
+		t.Fatalf("expected empty history, got %d entries", len(h.Entries()))
+	}
+
+	h.Record("a", "")
+	if err := h.Save(); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+
+	if _, err := os.Stat(historyPath); err != nil {
+		t.Fatalf("expected history file to be created: %v", err)
+	}
+}
+
+func TestLoadCorruptedFile(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "history.json")
+	if err := os.WriteFile(historyPath, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := Load(historyPath)
+	if err != nil {
+		t.Fatalf("expected corrupted history to be recovered, got %v", err)
+	}
+
+	if len(h.Entries()) != 0 {
+		t.Fatalf("expected empty history, got %d entries", len(h.Entries()))
+	}
+
+	bts, err := os.ReadFile(historyPath + ".corrupted")
+	if err != nil {
+		t.Fatalf("expected corrupted file to be kept: %v", err)
+	}
+
+	if string(bts) != "{not json" {
+		t.Fatalf("unexpected content for corrupted file: %s", bts)
+	}
+}
+
+func TestLoadUnreadableFile(t *testing.T) {
+	// a directory cannot be read as a file
+	historyPath := t.TempDir()
+
+	if _, err := Load(historyPath); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestLoadLegacyFormat(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "history.json")
+	if err := os.WriteFile(historyPath, []byte(`{"a": 1700000000}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := Load(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, ok := h.Entries()["a"]
+	if !ok {
+		t.Fatal("expected entry a to be migrated")
+	}
+
+	if entry.Count != 1 || entry.LastUsed != 1700000000 {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestRankOrdering(t *testing.T) {
+	now := time.Now()
+	h := New(filepath.Join(t.TempDir(), "history.json"))
+	h.Merge(map[string]Entry{
+		"old":      {Count: 10, LastUsed: now.Add(-30 * 24 * time.Hour).Unix()},
+		"frequent": {Count: 10, LastUsed: now.Add(-2 * time.Hour).Unix()},
+		"recent":   {Count: 1, LastUsed: now.Unix()},
+		"query":    {Count: 1, LastUsed: now.Add(-2 * time.Hour).Unix(), Queries: map[string]int{"git": 20}},
+	})
+
+	if !(h.Rank("frequent", "") > h.Rank("recent", "")) {
+		t.Error("expected an item used often to outrank an item used once")
+	}
+
+	if !(h.Rank("frequent", "") > h.Rank("old", "")) {
+		t.Error("expected an item used recently to outrank an item with the same count used long ago")
+	}
+
+	if h.Rank("unknown", "") != 0 {
+		t.Error("expected unknown items to have a zero rank")
+	}
+
+	if !(h.Rank("query", "gi") > h.Rank("frequent", "gi")) {
+		t.Error("expected the query affinity to boost the rank")
+	}
+
+	if !(h.Rank("query", "") < h.Rank("frequent", "")) {
+		t.Error("expected the query affinity to be ignored without query")
+	}
+}
+
+func TestSaveKeepsConcurrentChanges(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "history.json")
+
+	first, err := Load(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := Load(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first.Record("a", "")
+	if err := first.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	second.Record("a", "")
+	second.Record("b", "")
+	if err := second.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := Load(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count := h.Entries()["a"].Count; count != 2 {
+		t.Errorf("expected a to be recorded twice, got %d", count)
+	}
+
+	if count := h.Entries()["b"].Count; count != 1 {
+		t.Errorf("expected b to be recorded once, got %d", count)
+	}
+}
+
+func TestMergeKeepsLargestEntries(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "history.json")
+
+	h, err := Load(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.Record("a", "git")
+	h.Record("a", "git")
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	lastUsed := time.Now().Add(time.Hour).Unix()
+	exported := map[string]Entry{
+		"a": {Count: 1, LastUsed: lastUsed, Queries: map[string]int{"git": 1, "gh": 3}},
+		"b": {Count: 4, LastUsed: lastUsed},
+	}
+
+	// importing the same entries twice must not change the history
+	for i := 0; i < 2; i++ {
+		h, err := Load(historyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h.Merge(exported)
+		if err := h.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h, err = Load(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := h.Entries()["a"]
+	if a.Count != 2 || a.LastUsed != lastUsed {
+		t.Errorf("expected a to keep its count and the latest use, got %+v", a)
+	}
+
+	if a.Queries["git"] != 2 || a.Queries["gh"] != 3 {
+		t.Errorf("expected a to keep the largest query counts, got %v", a.Queries)
+	}
+
+	if b := h.Entries()["b"]; b.Count != 4 {
+		t.Errorf("expected b to be imported, got %+v", b)
+	}
+}
@@ -0,0 +1,55 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pomdtr/sunbeam/internal/utils"
+)
+
+var Path = filepath.Join(utils.CacheDir(), "session.json")
+
+// Session is the stack of pages open when sunbeam exited, the root page is not included.
+type Session struct {
+	Pages []Page `json:"pages"`
+}
+
+// Page is a command of an extension, along with the query typed in its list.
+// The preferences are not saved, they are loaded again when the page is restored.
+type Page struct {
+	Extension string         `json:"extension"`
+	Command   string         `json:"command"`
+	Params    map[string]any `json:"params,omitempty"`
+	Query     string         `json:"query,omitempty"`
+}
+
+// Load reads the session file, a missing file is an empty session.
+func Load(sessionPath string) (Session, error) {
+	bts, err := os.ReadFile(sessionPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Session{}, nil
+	} else if err != nil {
+		return Session{}, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(bts, &session); err != nil {
+		return Session{}, fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	return session, nil
+}
+
+func (s Session) Save(sessionPath string) error {
+	bts, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// params may contain private data
+	return utils.WriteFileAtomic(sessionPath, bts, 0600)
+}
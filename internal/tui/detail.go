@@ -3,13 +3,13 @@ package tui
 import (
 	"fmt"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/glamour/ansi"
-	"github.com/charmbracelet/glamour/styles"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/reflow/wordwrap"
 	"github.com/muesli/reflow/wrap"
@@ -27,6 +27,7 @@ type Detail struct {
 	input     textinput.Model
 
 	text          string
+	title         string
 	width, height int
 
 	Style    lipgloss.Style
@@ -34,7 +35,7 @@ type Detail struct {
 }
 
 func AnsiStyle() ansi.StyleConfig {
-	style := styles.PinkStyleConfig
+	style := theme.Markdown
 	style.Document.BlockPrefix = ""
 
 	return style
@@ -58,7 +59,7 @@ func NewDetail(text string, actions ...sunbeam.Action) *Detail {
 	filter.DrawLines = true
 
 	input := textinput.New()
-	input.PlaceholderStyle = lipgloss.NewStyle().Faint(true)
+	input.PlaceholderStyle = theme.FaintStyle()
 	input.Prompt = ""
 	input.Placeholder = "Search Actions..."
 
@@ -74,6 +75,10 @@ func NewDetail(text string, actions ...sunbeam.Action) *Detail {
 	return &d
 }
 
+func (d *Detail) Title() string {
+	return d.title
+}
+
 func (d *Detail) Init() tea.Cmd {
 	return nil
 }
@@ -103,8 +108,8 @@ func (d *Detail) SetIsLoading(isLoading bool) tea.Cmd {
 func (c *Detail) Update(msg tea.Msg) (Page, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "tab":
+		switch {
+		case keymap.Matches(msg, KeyShowActions):
 			if c.statusBar.expanded {
 				break
 			}
@@ -113,15 +118,14 @@ func (c *Detail) Update(msg tea.Msg) (Page, tea.Cmd) {
 				break
 			}
 
-			c.statusBar.expanded = true
-			return c, c.input.Focus()
-		case "q":
+			return c, c.expandActions()
+		case keymap.Matches(msg, KeyCloseDetail):
 			if c.actionsFocused {
 				break
 			}
 
 			return c, PopPageCmd
-		case "esc":
+		case keymap.Matches(msg, KeyBack):
 			if c.statusBar.expanded {
 				c.statusBar.Reset()
 				c.input.Blur()
@@ -131,6 +135,27 @@ func (c *Detail) Update(msg tea.Msg) (Page, tea.Cmd) {
 				return PopPageMsg{}
 			}
 		}
+	case tea.MouseMsg:
+		return c, c.handleMouse(msg)
+	case sunbeam.Action:
+		// pages embedded in a runner never receive their actions, the ones pushed on their own
+		// (like the errors of the commands run in the background) run the actions that don't need an extension
+		switch msg.Type {
+		case sunbeam.ActionTypeCopy:
+			return c, func() tea.Msg {
+				if err := clipboard.WriteAll(msg.Copy.Text); err != nil {
+					return err
+				}
+
+				if msg.Copy.Exit {
+					return ExitMsg{}
+				}
+
+				return ShowNotificationMsg{"Copied!"}
+			}
+		case sunbeam.ActionTypeExec:
+			return c, ExecCmd(msg.Exec, func() tea.Msg { return nil })
+		}
 	}
 	var cmds []tea.Cmd
 	var cmd tea.Cmd
@@ -155,6 +180,40 @@ func (c *Detail) Update(msg tea.Msg) (Page, tea.Cmd) {
 	return c, tea.Batch(cmds...)
 }
 
+func (c *Detail) expandActions() tea.Cmd {
+	c.statusBar.expanded = true
+	return c.input.Focus()
+}
+
+func (c *Detail) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	viewportZone := zone{x: 0, y: 2, width: c.viewport.Width, height: c.viewport.Height}
+	actionsZone := zone{x: 0, y: c.height - 1, width: c.width, height: 1}
+
+	switch {
+	case viewportZone.contains(msg) && isWheel(msg):
+		var cmd tea.Cmd
+		c.viewport, cmd = c.viewport.Update(msg)
+		return cmd
+	case actionsZone.contains(msg) && isClick(msg):
+		x, _ := actionsZone.relative(msg)
+		index, ok := c.statusBar.HitTest(x)
+		if !ok {
+			return nil
+		}
+
+		if index == -1 {
+			return c.expandActions()
+		}
+
+		action := c.statusBar.filtered[index]
+		return func() tea.Msg {
+			return action
+		}
+	}
+
+	return nil
+}
+
 func (c *Detail) RefreshContent() error {
 	var content string
 	if c.Markdown {
@@ -1,6 +1,13 @@
 package tui
 
-import "github.com/pomdtr/sunbeam/pkg/sunbeam"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pomdtr/sunbeam/internal/extensions"
+	"github.com/pomdtr/sunbeam/internal/utils"
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+)
 
 func NewErrorPage(err error, additionalActions ...sunbeam.Action) *Detail {
 	var actions []sunbeam.Action
@@ -14,7 +21,43 @@ func NewErrorPage(err error, additionalActions ...sunbeam.Action) *Detail {
 	})
 	actions = append(actions, additionalActions...)
 
+	// the path of the log is captured with the error, the next invocations write their own
+	if logPath := runLogPath(err); logPath != "" {
+		actions = append(actions, sunbeam.Action{
+			Title: "View Logs",
+			Key:   "l",
+			Type:  sunbeam.ActionTypeExec,
+			Exec: &sunbeam.ExecAction{
+				Command:     fmt.Sprintf("%s %s", utils.FindPager(), shellQuote(logPath)),
+				Interactive: true,
+			},
+		})
+	}
+
+	var timeoutErr extensions.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		detail := NewDetail(fmt.Sprintf("Command %s.\n\nThe extension did not answer in time and was killed. Use the timeout field of the extension config to raise the limit.", timeoutErr.Error()), actions...)
+		detail.title = "Error"
+		return detail
+	}
+
 	detail := NewDetail(err.Error(), actions...)
+	detail.title = "Error"
 
 	return detail
 }
+
+// runLogPath returns the log of the failed invocation of an extension command, if the error comes from one.
+func runLogPath(err error) string {
+	var timeoutErr extensions.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return timeoutErr.LogPath
+	}
+
+	var commandErr extensions.CommandError
+	if errors.As(err, &commandErr) {
+		return commandErr.LogPath
+	}
+
+	return ""
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+)
+
+func NewExecCmd(action *sunbeam.ExecAction) (*exec.Cmd, error) {
+	cmd := exec.Command("sh", "-c", action.Command)
+	cmd.Dir = action.Dir
+	if strings.HasPrefix(cmd.Dir, "~") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.Dir = filepath.Join(homeDir, strings.TrimPrefix(cmd.Dir, "~"))
+	}
+
+	if !filepath.IsAbs(cmd.Dir) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.Dir = filepath.Join(wd, cmd.Dir)
+	}
+
+	return cmd, nil
+}
+
+// ExecCmd runs an exec action, the output of non-interactive commands is shown as a notification.
+// Once an interactive command exits, onExit is called to restore the page.
+func ExecCmd(action *sunbeam.ExecAction, onExit func() tea.Msg) tea.Cmd {
+	cmd, err := NewExecCmd(action)
+	if err != nil {
+		return func() tea.Msg { return err }
+	}
+
+	if !action.Interactive {
+		return func() tea.Msg {
+			output, err := cmd.Output()
+			if err != nil {
+				return err
+			}
+
+			if action.Exit {
+				return ExitMsg{}
+			}
+
+			if len(output) > 0 {
+				output = bytes.Trim(output, "\n")
+				rows := strings.Split(string(output), "\n")
+				return ShowNotificationMsg{rows[len(rows)-1]}
+			}
+
+			return nil
+		}
+	}
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			return err
+		}
+
+		if action.Exit {
+			return ExitMsg{}
+		}
+
+		return onExit()
+	})
+}
+
+// shellQuote quotes an argument of a command run by sh.
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -12,7 +12,8 @@ import (
 
 type FilterItem interface {
 	FilterValue() string
-	Render(width int, selected bool) string
+	// Render renders the item, highlighting the runes of the filter value matched by the query
+	Render(width int, selected bool, matches []int) string
 	ID() string
 }
 
@@ -22,9 +23,20 @@ type Filter struct {
 	Query         string
 	Less          func(i, j FilterItem) bool
 	EmptyText     string
+	// Rank is used to break ties between items with the same fuzzy score
+	Rank func(item FilterItem, query string) float64
+	// Value replaces the filter value of the items when set
+	Value func(item FilterItem) string
+	// Render replaces the rendering of the items when set, it must match the layout of the Value hook
+	Render func(item FilterItem, width int, selected bool, matches []int) string
 
 	items    []FilterItem
 	filtered []FilterItem
+	// texts holds the converted filter values of the items, they are only computed once
+	texts []*fzf.Text
+	// pattern is the parsed query, and indexes the positions of the filtered items in items
+	pattern fzf.Pattern
+	indexes []int
 
 	DrawLines bool
 	cursor    int
@@ -60,6 +72,7 @@ func (f Filter) Selection() FilterItem {
 func (f *Filter) SetItems(items ...FilterItem) {
 	f.items = items
 	f.filtered = items
+	f.resetCache()
 
 	if f.cursor < 0 {
 		f.cursor = 0
@@ -70,29 +83,86 @@ func (f *Filter) SetItems(items ...FilterItem) {
 	}
 }
 
+func (f Filter) value(item FilterItem) string {
+	if f.Value != nil {
+		return f.Value(item)
+	}
+
+	return item.FilterValue()
+}
+
+// resetCache must be called when the filter values of the items change.
+func (f *Filter) resetCache() {
+	f.texts = nil
+	f.pattern = fzf.Pattern{}
+	f.indexes = nil
+}
+
 func (f *Filter) FilterItems(query string) {
+	previous := f.Query
 	f.Query = query
-	values := make([]string, len(f.items))
-	for i, choice := range f.items {
-		values[i] = choice.FilterValue()
-	}
+	f.pattern = fzf.ParsePattern(query)
 	// If the search field is empty, let's not display the matches
 	// (none), but rather display all possible choices.
-	if query == "" {
+	if f.pattern.Empty() {
 		f.filtered = f.items
+		f.indexes = nil
 	} else {
-		f.filtered = make([]FilterItem, 0)
-		for i := 0; i < len(f.items); i++ {
-			filterValue := f.items[i].FilterValue()
-			score := fzf.Score(filterValue, query)
-			if score > 0 {
-				f.filtered = append(f.filtered, f.items[i])
+		if f.texts == nil {
+			f.texts = make([]*fzf.Text, len(f.items))
+			for i, item := range f.items {
+				f.texts[i] = fzf.NewText(f.value(item))
+			}
+		}
+
+		// when the query extends the previous one, only the previous matches can match
+		candidates := f.indexes
+		if candidates == nil || !fzf.Narrows(previous, query) {
+			candidates = make([]int, len(f.items))
+			for i := range candidates {
+				candidates[i] = i
 			}
 		}
 
-		sort.SliceStable(f.filtered, func(i, j int) bool {
-			return fzf.Score(f.filtered[i].FilterValue(), query) > fzf.Score(f.filtered[j].FilterValue(), query)
+		type result struct {
+			index int
+			score int
+			rank  float64
+		}
+
+		results := make([]result, 0)
+		for _, index := range candidates {
+			ok, score, _ := f.pattern.MatchText(f.texts[index], false)
+			if !ok {
+				continue
+			}
+
+			res := result{index: index, score: score}
+			if f.Rank != nil {
+				res.rank = f.Rank(f.items[index], query)
+			}
+			results = append(results, res)
+		}
+
+		// ties are broken by the rank, then by the original order of the items
+		sort.Slice(results, func(i, j int) bool {
+			if results[i].score != results[j].score {
+				return results[i].score > results[j].score
+			}
+
+			if results[i].rank != results[j].rank {
+				return results[i].rank > results[j].rank
+			}
+
+			return results[i].index < results[j].index
 		})
+
+		f.filtered = make([]FilterItem, len(results))
+		f.indexes = make([]int, len(results))
+		for i, result := range results {
+			f.filtered[i] = f.items[result.index]
+			f.indexes[i] = result.index
+		}
 	}
 
 	if f.cursor >= len(f.filtered) {
@@ -108,6 +178,49 @@ func (f *Filter) Select(id string) {
 	}
 }
 
+// ItemAt returns the index of the item displayed at the given row of the filter, or -1.
+func (f Filter) ItemAt(row int) int {
+	if row < 0 || row >= f.Height || row%f.itemHeight() != 0 {
+		return -1
+	}
+
+	index := f.minIndex + row/f.itemHeight()
+	if index >= len(f.filtered) {
+		return -1
+	}
+
+	return index
+}
+
+func (f *Filter) SetCursor(index int) {
+	if index < 0 || index >= len(f.filtered) {
+		return
+	}
+
+	f.cursor = index
+	if f.cursor < f.minIndex {
+		f.minIndex = f.cursor
+	} else if f.cursor >= f.minIndex+f.nbVisibleItems() {
+		f.minIndex = f.cursor - f.nbVisibleItems() + 1
+	}
+}
+
+func (m Filter) render(index int, width int) string {
+	item := m.filtered[index]
+
+	// the positions are only computed for the visible items
+	var matches []int
+	if index < len(m.indexes) {
+		_, _, matches = m.pattern.MatchText(m.texts[m.indexes[index]], true)
+	}
+
+	if m.Render != nil {
+		return m.Render(item, width, index == m.cursor, matches)
+	}
+
+	return item.Render(width, index == m.cursor, matches)
+}
+
 func (m Filter) Init() tea.Cmd { return nil }
 
 func (m Filter) View() string {
@@ -124,7 +237,7 @@ func (m Filter) View() string {
 			emptyText = "No Items"
 		}
 
-		emptyText = lipgloss.NewStyle().Faint(true).Render(emptyText)
+		emptyText = theme.FaintStyle().Render(emptyText)
 		return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, emptyText)
 	}
 
@@ -135,8 +248,7 @@ func (m Filter) View() string {
 	}
 
 	for nbVisibleItems > 0 && index < len(m.filtered) {
-		item := m.filtered[index]
-		itemView := item.Render(itemWidth, index == m.cursor)
+		itemView := m.render(index, itemWidth)
 		rows = append(rows, itemView)
 
 		index++
@@ -144,7 +256,7 @@ func (m Filter) View() string {
 
 		if m.DrawLines && index < len(m.filtered) && nbVisibleItems > 0 {
 			separator := strings.Repeat("─", itemWidth)
-			separator = lipgloss.NewStyle().Faint(true).Render(separator)
+			separator = theme.SeparatorStyle().Bold(false).Faint(true).Render(separator)
 			rows = append(rows, separator)
 		}
 	}
@@ -161,22 +273,33 @@ func (m Filter) View() string {
 func (f Filter) Update(msg tea.Msg) (Filter, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "down", "ctrl+j", "ctrl+n":
+		switch {
+		case keymap.Matches(msg, KeyCursorDown):
 			f.CursorDown()
-		case "up", "ctrl+k", "ctrl+p":
+		case keymap.Matches(msg, KeyCursorUp):
 			f.CursorUp()
-		case "ctrl+u":
+		case keymap.Matches(msg, KeyPageUp):
 			shift := min(f.nbVisibleItems(), f.cursor)
 			for i := 0; i < shift; i++ {
 				f.CursorUp()
 			}
-		case "ctrl+d":
+		case keymap.Matches(msg, KeyPageDown):
 			shift := min(f.nbVisibleItems(), len(f.filtered)-f.cursor-1)
 			for i := 0; i < shift; i++ {
 				f.CursorDown()
 			}
 		}
+	case tea.MouseMsg:
+		if !isWheel(msg) {
+			break
+		}
+
+		// the wheel does not wrap around
+		if msg.Button == tea.MouseButtonWheelUp {
+			f.SetCursor(f.cursor - 1)
+		} else {
+			f.SetCursor(f.cursor + 1)
+		}
 	}
 
 	return f, nil
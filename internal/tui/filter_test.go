@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+)
+
+var benchmarkWords = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+	"india", "juliett", "kilo", "lima", "mike", "november", "oscar", "papa",
+	"quebec", "romeo", "sierra", "tango", "uniform", "victor", "whiskey", "xray",
+}
+
+// benchmarkItems generates items looking like the ones of the file and bookmark extensions.
+func benchmarkItems(n int) []FilterItem {
+	items := make([]FilterItem, n)
+	for i := range items {
+		word := func(seed int) string {
+			return benchmarkWords[seed%len(benchmarkWords)]
+		}
+
+		items[i] = ListItem{
+			Title:       fmt.Sprintf("%s-%s-%d.md", word(i), word(i/len(benchmarkWords)), i),
+			Subtitle:    fmt.Sprintf("~/notes/%s/%s", word(i*7), word(i*13)),
+			Accessories: []string{word(i * 3)},
+		}
+	}
+
+	return items
+}
+
+func filteredIDs(f Filter) []string {
+	ids := make([]string, len(f.filtered))
+	for i, item := range f.filtered {
+		ids[i] = item.ID()
+	}
+
+	return ids
+}
+
+func TestFilterItemsNarrowing(t *testing.T) {
+	items := benchmarkItems(5_000)
+	for _, query := range []string{"bravo-de", "^echo-g", "notes/kilo", "golf 12", "'lima-x"} {
+		incremental := NewFilter(items...)
+		for i := 1; i <= len(query); i++ {
+			incremental.FilterItems(query[:i])
+		}
+
+		full := NewFilter(items...)
+		full.FilterItems(query)
+
+		expected, actual := filteredIDs(full), filteredIDs(incremental)
+		if len(expected) != len(actual) {
+			t.Fatalf("query %q: expected %d items, got %d", query, len(expected), len(actual))
+		}
+
+		for i := range expected {
+			if expected[i] != actual[i] {
+				t.Fatalf("query %q: expected %s at index %d, got %s", query, expected[i], i, actual[i])
+			}
+		}
+	}
+}
+
+func TestFilterItemsWidening(t *testing.T) {
+	items := benchmarkItems(1_000)
+	f := NewFilter(items...)
+
+	// each query can match items rejected by the previous one
+	for _, query := range []string{"!alpha", "!alphab", "bravo", "bravo | charlie", "hotel$", "hotel$x", "hotel"} {
+		f.FilterItems(query)
+
+		full := NewFilter(items...)
+		full.FilterItems(query)
+		if len(f.filtered) != len(full.filtered) {
+			t.Fatalf("query %q: expected %d items, got %d", query, len(full.filtered), len(f.filtered))
+		}
+	}
+}
+
+func TestFilterItemsValueChange(t *testing.T) {
+	f := NewFilter(benchmarkItems(100)...)
+	f.FilterItems("notes")
+	if len(f.filtered) != 100 {
+		t.Fatalf("expected the subtitle to be matched, got %d items", len(f.filtered))
+	}
+
+	f.Value = func(item FilterItem) string {
+		return item.(ListItem).filterValue(nil)
+	}
+	f.resetCache()
+	f.FilterItems("notes")
+	if len(f.filtered) != 0 {
+		t.Fatalf("expected the subtitle to be ignored, got %d items", len(f.filtered))
+	}
+}
+
+// typeQuery filters the items for each prefix of the query, the way they are filtered while it is typed.
+func typeQuery(b *testing.B, items []FilterItem, query string) {
+	b.Helper()
+
+	f := NewFilter(items...)
+	// convert the filter values before starting the timer, they are cached once the list is loaded
+	f.FilterItems(query[:1])
+	f.FilterItems("")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 1; j <= len(query); j++ {
+			f.FilterItems(query[:j])
+		}
+		f.FilterItems("")
+	}
+
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(query)), "ns/keystroke")
+}
+
+func BenchmarkFilterKeystroke(b *testing.B) {
+	items := benchmarkItems(100_000)
+
+	b.Run("fuzzy", func(b *testing.B) {
+		typeQuery(b, items, "bravokilo")
+	})
+
+	b.Run("exact", func(b *testing.B) {
+		typeQuery(b, items, "'notes/lima")
+	})
+
+	b.Run("extended", func(b *testing.B) {
+		typeQuery(b, items, "^echo .md$ notes/")
+	})
+}
+
+// BenchmarkFilterBackspace measures the keystrokes that can't reuse the previous matches.
+func BenchmarkFilterBackspace(b *testing.B) {
+	f := NewFilter(benchmarkItems(100_000)...)
+	f.FilterItems("bravo")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%2 == 0 {
+			f.FilterItems("brav")
+		} else {
+			f.FilterItems("bravo")
+		}
+	}
+}
+
+// BenchmarkFilterFirstKeystroke includes the conversion of the filter values of a newly loaded list.
+func BenchmarkFilterFirstKeystroke(b *testing.B) {
+	items := benchmarkItems(100_000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f := NewFilter(items...)
+		f.FilterItems("b")
+	}
+}
+
+func BenchmarkFilterView(b *testing.B) {
+	f := NewFilter(benchmarkItems(100_000)...)
+	f.SetSize(100, 30)
+	f.FilterItems("bravokilo")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = f.View()
+	}
+}
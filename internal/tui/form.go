@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/pomdtr/sunbeam/internal/config"
 	"github.com/pomdtr/sunbeam/internal/extensions"
 	"github.com/pomdtr/sunbeam/pkg/sunbeam"
 )
@@ -31,8 +32,7 @@ type Form struct {
 func ExtractPreferencesFromEnv(alias string, extension extensions.Extension) (map[string]any, error) {
 	var preferences = make(map[string]any)
 	for _, input := range extension.Manifest.Preferences {
-		env := fmt.Sprintf("%s_%s", strings.ToUpper(alias), strings.ToUpper(input.Name))
-		env = strings.ReplaceAll(env, "-", "_")
+		env := extensions.PreferenceEnv(alias, input.Name)
 		if value, ok := os.LookupEnv(env); ok {
 			switch input.Type {
 			case sunbeam.InputString:
@@ -59,6 +59,57 @@ func ExtractPreferencesFromEnv(alias string, extension extensions.Extension) (ma
 	return preferences, nil
 }
 
+// LoadPreferences merges the preferences stored in the extension config with the ones set in the environment.
+func LoadPreferences(alias string, extension extensions.Extension) (map[string]any, error) {
+	preferences := make(map[string]any)
+	for name, value := range extension.Config.Preferences {
+		preferences[name] = value
+	}
+
+	envs, err := ExtractPreferencesFromEnv(alias, extension)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range envs {
+		preferences[name] = value
+	}
+
+	return preferences, nil
+}
+
+// SavePreferences stores the preferences submitted by a form in the extension config.
+// The values of secret preferences are moved to the secret store, and replaced by a reference.
+func SavePreferences(cfg config.Config, alias string, extension extensions.Extension, values map[string]any) error {
+	extensionConfig := cfg.Extensions[alias]
+	preferences := make(map[string]any)
+	for name, value := range extensionConfig.Preferences {
+		preferences[name] = value
+	}
+
+	for _, input := range extension.Manifest.Preferences {
+		value, ok := values[input.Name]
+		if !ok {
+			continue
+		}
+
+		if !input.Secret || value == nil || config.IsReference(value) {
+			preferences[input.Name] = value
+			continue
+		}
+
+		key := config.SecretKey(alias, input.Name)
+		if err := cfg.SecretStore().Set(key, fmt.Sprint(value)); err != nil {
+			return err
+		}
+		preferences[input.Name] = config.SecretReference(key)
+	}
+
+	extensionConfig.Preferences = preferences
+	cfg.Extensions[alias] = extensionConfig
+	return cfg.Save()
+}
+
 func FindMissingPreferences(preferenceInputs []sunbeam.Input, values map[string]any) []sunbeam.Input {
 	preferenceParams := make(map[string]any)
 	for name, value := range values {
@@ -94,7 +145,7 @@ func NewForm(submitMsg func(map[string]any) tea.Msg, params ...sunbeam.Input) *F
 	for _, param := range params {
 		switch param.Type {
 		case sunbeam.InputString:
-			inputs = append(inputs, NewTextField(param, false))
+			inputs = append(inputs, NewTextField(param, param.Secret))
 		case sunbeam.InputBoolean:
 			inputs = append(inputs, NewCheckbox(param))
 		case sunbeam.InputNumber:
@@ -175,16 +226,14 @@ func (c *Form) ScrollViewport() {
 func (c Form) Update(msg tea.Msg) (Page, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case keymap.Matches(msg, KeyBack):
 			return &c, func() tea.Msg {
 				return PopPageMsg{}
 			}
 		// Set focus to next input
-		case "tab", "shift+tab":
-			s := msg.String()
-
-			if s == "up" || s == "shift+tab" {
+		case keymap.Matches(msg, KeyNextInput), keymap.Matches(msg, KeyPreviousInput):
+			if keymap.Matches(msg, KeyPreviousInput) {
 				c.focusIndex--
 			} else {
 				c.focusIndex++
@@ -214,7 +263,7 @@ func (c Form) Update(msg tea.Msg) (Page, tea.Cmd) {
 			}
 
 			return &c, tea.Batch(cmds...)
-		case "alt+enter":
+		case keymap.Matches(msg, KeySubmit):
 			return &c, func() tea.Msg {
 				values := make(map[string]any)
 				for _, input := range c.inputs {
@@ -237,7 +286,7 @@ func (c Form) Update(msg tea.Msg) (Page, tea.Cmd) {
 }
 
 func (c *Form) renderInputs() {
-	selectedBorder := lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), true).BorderForeground(lipgloss.Color("13"))
+	selectedBorder := lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), true).BorderForeground(theme.Accent)
 	normalBorder := lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), true)
 	itemViews := make([]string, len(c.inputs))
 	maxWidth := 0
@@ -296,6 +345,6 @@ func (c *Form) SetSize(width, height int) {
 
 func (c *Form) View() string {
 	separator := strings.Repeat("─", c.width)
-	submitRow := lipgloss.NewStyle().Align(lipgloss.Right).Padding(0, 1).Width(c.width).Render(fmt.Sprintf("%s · %s", renderAction("Submit", "alt+enter", false), renderAction("Focus Next", "tab", false)))
+	submitRow := lipgloss.NewStyle().Align(lipgloss.Right).Padding(0, 1).Width(c.width).Render(fmt.Sprintf("%s · %s", renderAction("Submit", keymap.Key(KeySubmit), false), renderAction("Focus Next", keymap.Key(KeyNextInput), false)))
 	return lipgloss.JoinVertical(lipgloss.Left, c.viewport.View(), separator, submitRow)
 }
@@ -10,7 +10,6 @@ import (
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"github.com/pomdtr/sunbeam/pkg/sunbeam"
 )
 
@@ -50,7 +49,7 @@ func NewTextField(input sunbeam.Input, secure bool) *TextField {
 		}
 	}
 
-	ti.PlaceholderStyle = lipgloss.NewStyle().Faint(true)
+	ti.PlaceholderStyle = theme.FaintStyle()
 
 	return &TextField{
 		name:        input.Name,
@@ -86,8 +85,8 @@ func (ti *TextField) Value() any {
 func (ti *TextField) Update(msg tea.Msg) (Input, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+e":
+		switch {
+		case keymap.Matches(msg, KeyOpenEditor):
 			if !ti.Model.Focused() {
 				break
 			}
@@ -165,8 +164,8 @@ func (ta *TextArea) Value() any {
 func (ta *TextArea) Update(msg tea.Msg) (Input, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+e":
+		switch {
+		case keymap.Matches(msg, KeyOpenEditor):
 			if !ta.Model.Focused() {
 				break
 			}
@@ -0,0 +1,311 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pomdtr/sunbeam/internal/config"
+)
+
+type KeyCommand string
+
+const (
+	KeyQuit             KeyCommand = "quit"
+	KeyHelp             KeyCommand = "help"
+	KeyBack             KeyCommand = "back"
+	KeyBackToRoot       KeyCommand = "back-to-root"
+	KeyPageStack        KeyCommand = "page-stack"
+	KeyClose            KeyCommand = "close"
+	KeyEdit             KeyCommand = "edit"
+	KeyReload           KeyCommand = "reload"
+	KeyCursorDown       KeyCommand = "cursor-down"
+	KeyCursorUp         KeyCommand = "cursor-up"
+	KeyPageDown         KeyCommand = "page-down"
+	KeyPageUp           KeyCommand = "page-up"
+	KeyToggleDetail     KeyCommand = "toggle-detail"
+	KeyTogglePalette    KeyCommand = "toggle-palette"
+	KeyScrollDetailDown KeyCommand = "scroll-detail-down"
+	KeyScrollDetailUp   KeyCommand = "scroll-detail-up"
+	KeyShowActions      KeyCommand = "show-actions"
+	KeyNextAction       KeyCommand = "next-action"
+	KeyPreviousAction   KeyCommand = "previous-action"
+	KeyPrimaryAction    KeyCommand = "primary-action"
+	KeySecondaryAction  KeyCommand = "secondary-action"
+	KeyActionModifier   KeyCommand = "action-modifier"
+	KeyCloseDetail      KeyCommand = "close-detail"
+	KeySubmit           KeyCommand = "submit"
+	KeyNextInput        KeyCommand = "next-input"
+	KeyPreviousInput    KeyCommand = "previous-input"
+	KeyOpenEditor       KeyCommand = "open-editor"
+)
+
+// Key scopes, bindings of the same scope (or of the global scope) can't share a key.
+const (
+	ScopeGlobal = "global"
+	ScopeList   = "list"
+	// the items of a list, the keys of the list scope take precedence over the ones of the filter
+	ScopeFilter  = "filter"
+	ScopeActions = "actions"
+	ScopeDetail  = "detail"
+	ScopeForm    = "form"
+)
+
+type KeyBinding struct {
+	Command     KeyCommand
+	Description string
+	Keys        []string
+	Scopes      []string
+}
+
+var defaultBindings = []KeyBinding{
+	{Command: KeyQuit, Description: "Quit sunbeam", Keys: []string{"ctrl+c"}, Scopes: []string{ScopeGlobal}},
+	{Command: KeyHelp, Description: "Show the keybindings", Keys: []string{"f1"}, Scopes: []string{ScopeGlobal}},
+	{Command: KeyBack, Description: "Go back to the previous page", Keys: []string{"esc"}, Scopes: []string{ScopeGlobal}},
+	{Command: KeyBackToRoot, Description: "Go back to the first page", Keys: []string{"ctrl+g"}, Scopes: []string{ScopeGlobal}},
+	{Command: KeyPageStack, Description: "List the open pages", Keys: []string{"ctrl+o"}, Scopes: []string{ScopeGlobal}},
+	{Command: KeyClose, Description: "Close the current page", Keys: []string{"ctrl+d"}, Scopes: []string{ScopeList, ScopeDetail}},
+	{Command: KeyEdit, Description: "Edit the config or the extension", Keys: []string{"ctrl+s"}, Scopes: []string{ScopeList, ScopeDetail}},
+	{Command: KeyReload, Description: "Reload the page", Keys: []string{"ctrl+r"}, Scopes: []string{ScopeList, ScopeDetail}},
+	{Command: KeyCursorDown, Description: "Select the next item", Keys: []string{"down", "ctrl+j", "ctrl+n"}, Scopes: []string{ScopeFilter}},
+	{Command: KeyCursorUp, Description: "Select the previous item", Keys: []string{"up", "ctrl+k", "ctrl+p"}, Scopes: []string{ScopeFilter}},
+	{Command: KeyPageDown, Description: "Select the item one page down", Keys: []string{"ctrl+d"}, Scopes: []string{ScopeFilter}},
+	{Command: KeyPageUp, Description: "Select the item one page up", Keys: []string{"ctrl+u"}, Scopes: []string{ScopeFilter}},
+	{Command: KeyToggleDetail, Description: "Toggle the detail of the items", Keys: []string{"ctrl+p"}, Scopes: []string{ScopeList}},
+	{Command: KeyTogglePalette, Description: "Switch the root list to the command palette and back", Keys: []string{"ctrl+l"}, Scopes: []string{ScopeList}},
+	{Command: KeyScrollDetailDown, Description: "Scroll the detail down, when it is shown", Keys: []string{"ctrl+j"}, Scopes: []string{ScopeList}},
+	{Command: KeyScrollDetailUp, Description: "Scroll the detail up, when it is shown", Keys: []string{"ctrl+k"}, Scopes: []string{ScopeList}},
+	{Command: KeyShowActions, Description: "Show the actions", Keys: []string{"tab"}, Scopes: []string{ScopeList, ScopeDetail}},
+	{Command: KeyNextAction, Description: "Select the next action", Keys: []string{"tab", "right"}, Scopes: []string{ScopeActions}},
+	{Command: KeyPreviousAction, Description: "Select the previous action", Keys: []string{"shift+tab", "left"}, Scopes: []string{ScopeActions}},
+	{Command: KeyPrimaryAction, Description: "Run the selected action", Keys: []string{"enter"}, Scopes: []string{ScopeList, ScopeDetail, ScopeActions}},
+	{Command: KeySecondaryAction, Description: "Run the second action", Keys: []string{"alt+enter"}, Scopes: []string{ScopeList, ScopeDetail, ScopeActions}},
+	{Command: KeyActionModifier, Description: "Modifier combined with the key of an action to run it", Keys: []string{"alt"}, Scopes: []string{ScopeList, ScopeDetail, ScopeActions}},
+	{Command: KeyCloseDetail, Description: "Close the detail page", Keys: []string{"q"}, Scopes: []string{ScopeDetail}},
+	{Command: KeySubmit, Description: "Submit the form", Keys: []string{"alt+enter"}, Scopes: []string{ScopeForm}},
+	{Command: KeyNextInput, Description: "Focus the next input", Keys: []string{"tab"}, Scopes: []string{ScopeForm}},
+	{Command: KeyPreviousInput, Description: "Focus the previous input", Keys: []string{"shift+tab"}, Scopes: []string{ScopeForm}},
+	{Command: KeyOpenEditor, Description: "Edit the input in your editor", Keys: []string{"ctrl+e"}, Scopes: []string{ScopeForm}},
+}
+
+// Keymap maps the commands of the interface to the keys triggering them.
+type Keymap struct {
+	bindings []KeyBinding
+}
+
+// keymap is used by every page, it is replaced by the one of the config when sunbeam starts.
+var keymap = DefaultKeymap()
+
+func SetKeymap(k Keymap) {
+	keymap = k
+}
+
+func DefaultKeymap() Keymap {
+	bindings := make([]KeyBinding, len(defaultBindings))
+	copy(bindings, defaultBindings)
+	return Keymap{bindings: bindings}
+}
+
+// NewKeymap overrides the default keys of the given commands, and checks that
+// no key triggers two commands of the same scope.
+func NewKeymap(overrides map[string]config.Keys) (Keymap, error) {
+	k := DefaultKeymap()
+	for command, keys := range overrides {
+		idx := k.index(KeyCommand(command))
+		if idx == -1 {
+			return Keymap{}, fmt.Errorf("unknown keymap command: %s", command)
+		}
+
+		normalized := make([]string, len(keys))
+		for i, key := range keys {
+			normalized[i] = normalizeKey(key)
+		}
+		k.bindings[idx].Keys = normalized
+	}
+
+	if err := k.validate(); err != nil {
+		return Keymap{}, err
+	}
+
+	return k, nil
+}
+
+// modifiers are the prefixes of the keys combined with a modifier, as reported by bubbletea.
+var modifiers = []string{"ctrl", "alt", "shift"}
+
+// normalizeKey lowercases the modifiers of a key, the name of the key itself is case sensitive.
+func normalizeKey(key string) string {
+	key = strings.TrimSpace(key)
+	if strings.EqualFold(key, "space") {
+		return " "
+	}
+
+	var prefix string
+	for {
+		i := strings.Index(key, "+")
+		if i == -1 || i == len(key)-1 || !isModifier(key[:i]) {
+			return prefix + key
+		}
+
+		prefix += strings.ToLower(key[:i+1])
+		key = key[i+1:]
+	}
+}
+
+func isModifier(name string) bool {
+	for _, modifier := range modifiers {
+		if strings.EqualFold(name, modifier) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (k Keymap) index(command KeyCommand) int {
+	for i, binding := range k.bindings {
+		if binding.Command == command {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (k Keymap) validate() error {
+	var conflicts []string
+	for i, a := range k.bindings {
+		for _, b := range k.bindings[i+1:] {
+			if !overlap(a.Scopes, b.Scopes) {
+				continue
+			}
+
+			if a.Command == KeyActionModifier || b.Command == KeyActionModifier {
+				conflicts = append(conflicts, modifierConflicts(a, b)...)
+				continue
+			}
+
+			for _, key := range a.Keys {
+				for _, other := range b.Keys {
+					if key == other {
+						conflicts = append(conflicts, fmt.Sprintf("%s is bound to both %s and %s", key, a.Command, b.Command))
+					}
+				}
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("keymap conflicts: %s", strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// modifierConflicts returns the keys of a binding shadowing an action shortcut, the keys
+// of actions are single characters combined with the action modifier.
+func modifierConflicts(a KeyBinding, b KeyBinding) []string {
+	if b.Command == KeyActionModifier {
+		a, b = b, a
+	}
+
+	var conflicts []string
+	for _, modifier := range a.Keys {
+		for _, key := range b.Keys {
+			if rest, ok := strings.CutPrefix(key, modifier+"+"); ok && utf8.RuneCountInString(rest) == 1 {
+				conflicts = append(conflicts, fmt.Sprintf("%s is bound to both %s and the actions with the %s key", key, b.Command, rest))
+			}
+		}
+	}
+
+	return conflicts
+}
+
+func overlap(a []string, b []string) bool {
+	for _, scope := range a {
+		for _, other := range b {
+			if scope == other || scope == ScopeGlobal || other == ScopeGlobal {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Matches reports whether the key message triggers the command.
+func (k Keymap) Matches(msg tea.KeyMsg, command KeyCommand) bool {
+	idx := k.index(command)
+	if idx == -1 {
+		return false
+	}
+
+	for _, key := range k.bindings[idx].Keys {
+		if msg.String() == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Key returns the main key of the command, used as a hint in the views.
+func (k Keymap) Key(command KeyCommand) string {
+	idx := k.index(command)
+	if idx == -1 || len(k.bindings[idx].Keys) == 0 {
+		return ""
+	}
+
+	return k.bindings[idx].Keys[0]
+}
+
+// ActionKey returns the key running an action with the given shortcut, or an empty string
+// if the action shortcuts are disabled.
+func (k Keymap) ActionKey(key string) string {
+	modifier := k.Key(KeyActionModifier)
+	if key == "" || modifier == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s+%s", modifier, key)
+}
+
+func (k Keymap) Bindings() []KeyBinding {
+	return k.bindings
+}
+
+// View renders the active bindings, it is shown as an overlay by the paginator.
+func (k Keymap) View(width, height int) string {
+	commandWidth, keysWidth := 0, len("none")
+	for _, binding := range k.bindings {
+		commandWidth = max(commandWidth, len(binding.Command))
+		keysWidth = max(keysWidth, lipgloss.Width(strings.Join(binding.Keys, ", ")))
+	}
+
+	rows := []string{lipgloss.NewStyle().Bold(true).Render("Keybindings"), ""}
+	for _, binding := range k.bindings {
+		keys := strings.Join(binding.Keys, ", ")
+		if keys == "" {
+			keys = "none"
+		}
+
+		rows = append(rows, fmt.Sprintf(
+			"%s  %s  %s",
+			lipgloss.NewStyle().Width(commandWidth).Render(string(binding.Command)),
+			lipgloss.NewStyle().Width(keysWidth).Bold(true).Render(keys),
+			theme.FaintStyle().Render(binding.Description),
+		))
+	}
+
+	// keep room for the border
+	if len(rows) > height-2 {
+		rows = rows[:max(height-2, 0)]
+	}
+
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1).Render(strings.Join(rows, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
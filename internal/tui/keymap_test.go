@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pomdtr/sunbeam/internal/config"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	testCases := map[string]string{
+		"ctrl+d":      "ctrl+d",
+		"Ctrl+D":      "ctrl+D",
+		"G":           "G",
+		"ALT+Enter":   "alt+Enter",
+		" shift+up ":  "shift+up",
+		"ctrl+alt+a":  "ctrl+alt+a",
+		"CTRL+ALT+B":  "ctrl+alt+B",
+		"Space":       " ",
+		"alt++":       "alt++",
+		"unknown+key": "unknown+key",
+	}
+
+	for key, expected := range testCases {
+		if actual := normalizeKey(key); actual != expected {
+			t.Errorf("normalizeKey(%q): expected %q, got %q", key, expected, actual)
+		}
+	}
+}
+
+func TestDefaultKeymap(t *testing.T) {
+	if err := DefaultKeymap().validate(); err != nil {
+		t.Fatalf("expected the default keymap to be valid, got %v", err)
+	}
+}
+
+func TestNewKeymapConflicts(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides map[string]config.Keys
+		conflict  string
+	}{
+		{
+			name:      "same scope",
+			overrides: map[string]config.Keys{"reload": {"ctrl+s"}},
+			conflict:  "ctrl+s is bound to both edit and reload",
+		},
+		{
+			name:      "global scope",
+			overrides: map[string]config.Keys{"close-detail": {"ctrl+c"}},
+			conflict:  "ctrl+c is bound to both quit and close-detail",
+		},
+		{
+			name:      "action modifier",
+			overrides: map[string]config.Keys{"action-modifier": {"ctrl"}},
+			conflict:  "ctrl+r is bound to both reload and the actions with the r key",
+		},
+		{
+			name:      "action shortcut",
+			overrides: map[string]config.Keys{"toggle-detail": {"alt+p"}},
+			conflict:  "alt+p is bound to both toggle-detail and the actions with the p key",
+		},
+		{
+			name:      "list and filter scopes",
+			overrides: map[string]config.Keys{"toggle-detail": {"ctrl+n"}, "action-modifier": {}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewKeymap(tc.overrides)
+			if tc.conflict == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.conflict) {
+				t.Fatalf("expected conflict %q, got %v", tc.conflict, err)
+			}
+		})
+	}
+}
@@ -36,6 +36,7 @@ type List struct {
 	autoRefreshTriggered bool
 
 	focus         ListFocus
+	clicks        clickTracker
 	Actions       []sunbeam.Action
 	OnQueryChange func(string) tea.Cmd
 	OnSelect      func(string) tea.Cmd
@@ -63,7 +64,7 @@ func NewList(items ...sunbeam.ListItem) *List {
 
 	input := textinput.New()
 	input.Prompt = ""
-	input.PlaceholderStyle = lipgloss.NewStyle().Faint(true)
+	input.PlaceholderStyle = theme.FaintStyle()
 	input.Placeholder = "Search Items..."
 
 	viewport := viewport.Model{}
@@ -135,6 +136,27 @@ func (l *List) SetActions(actions ...sunbeam.Action) {
 	}
 }
 
+// SetFilterBy sets the fields matched by the query in addition to the title and the keywords.
+// The subtitle is matched when no fields are set.
+func (l *List) SetFilterBy(fields []string) {
+	if fields == nil {
+		l.filter.Value = nil
+		l.filter.Render = nil
+	} else {
+		l.filter.Value = func(item FilterItem) string {
+			return item.(ListItem).filterValue(fields)
+		}
+		l.filter.Render = func(item FilterItem, width int, selected bool, matches []int) string {
+			return item.(ListItem).render(width, selected, fields, matches)
+		}
+	}
+	l.filter.resetCache()
+
+	if l.OnQueryChange == nil {
+		l.FilterItems(l.Query())
+	}
+}
+
 func (l *List) SetEmptyText(text string) {
 	l.filter.EmptyText = text
 }
@@ -285,8 +307,8 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 		}
 		return c, nil
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case keymap.Matches(msg, KeyBack):
 			if c.statusBar.expanded {
 				c.focus = ListFocusItems
 				c.input.SetValue(c.query)
@@ -301,42 +323,30 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 			}
 
 			return c, PopPageCmd
-		case "ctrl+j":
+		case keymap.Matches(msg, KeyScrollDetailDown):
 			if !c.showDetail {
 				break
 			}
 
 			c.viewport.LineDown(1)
 			return c, nil
-		case "ctrl+p":
+		case keymap.Matches(msg, KeyToggleDetail):
 			c.SetShowDetail(!c.showDetail)
 			return c, nil
-		case "ctrl+k":
+		case keymap.Matches(msg, KeyScrollDetailUp):
 			if !c.showDetail {
 				break
 			}
 
 			c.viewport.LineUp(1)
 			return c, nil
-		case "tab":
-			if c.statusBar.expanded {
-				break
-			}
-
-			selection, ok := c.Selection()
-			if ok && len(selection.Actions) < 2 {
-				break
-			}
-			if !ok && len(c.Actions) < 2 {
+		case keymap.Matches(msg, KeyShowActions):
+			if c.statusBar.expanded || !c.expandActions() {
 				break
 			}
 
-			c.input.SetValue("")
-			c.input.Placeholder = "Search Actions..."
-			c.statusBar.expanded = true
-			c.focus = ListFocusActions
 			return c, nil
-		case "right", "left":
+		case keymap.Matches(msg, KeyNextAction), keymap.Matches(msg, KeyPreviousAction):
 			if c.statusBar.expanded {
 				statusBar, cmd := c.statusBar.Update(msg)
 				c.statusBar = statusBar
@@ -347,6 +357,10 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 			c.input = input
 			return c, cmd
 		}
+	case tea.MouseMsg:
+		if cmd, ok := c.handleMouse(msg); ok {
+			return c, cmd
+		}
 	case QueryChangeMsg:
 		if c.OnQueryChange == nil {
 			return c, nil
@@ -378,6 +392,30 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 	cmds = append(cmds, cmd)
 
 	filter, cmd := c.filter.Update(msg)
+	c.setFilter(filter)
+	cmds = append(cmds, cmd)
+
+	if c.autoRefreshSeconds > 0 && !c.autoRefreshTriggered {
+		c.autoRefreshTriggered = true
+		cmd := tea.Tick(time.Duration(c.autoRefreshSeconds)*time.Second, func(t time.Time) tea.Msg {
+			return tickMsg{
+				id:   c.id,
+				time: t,
+			}
+		})
+		cmds = append(cmds, cmd)
+	}
+
+	if c.isLoading {
+		c.spinner, cmd = c.spinner.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	return c, tea.Batch(cmds...)
+}
+
+// setFilter replaces the filter, and updates the actions and the detail when the selection changes.
+func (c *List) setFilter(filter Filter) {
 	oldSelection := c.filter.Selection()
 	newSelection := filter.Selection()
 	if newSelection == nil {
@@ -395,25 +433,107 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 		c.statusBar.SetActions(newSelection.(ListItem).Actions...)
 	}
 	c.filter = filter
-	cmds = append(cmds, cmd)
+}
 
-	if c.autoRefreshSeconds > 0 && !c.autoRefreshTriggered {
-		c.autoRefreshTriggered = true
-		cmd := tea.Tick(time.Duration(c.autoRefreshSeconds)*time.Second, func(t time.Time) tea.Msg {
-			return tickMsg{
-				id:   c.id,
-				time: t,
-			}
-		})
-		cmds = append(cmds, cmd)
+// expandActions shows the actions of the selection in the status bar, if there is more than one.
+func (c *List) expandActions() bool {
+	selection, ok := c.Selection()
+	if ok && len(selection.Actions) < 2 {
+		return false
+	}
+	if !ok && len(c.Actions) < 2 {
+		return false
 	}
 
-	if c.isLoading {
-		c.spinner, cmd = c.spinner.Update(msg)
-		cmds = append(cmds, cmd)
+	c.input.SetValue("")
+	c.input.Placeholder = "Search Actions..."
+	c.statusBar.expanded = true
+	c.focus = ListFocusActions
+	return true
+}
+
+// zones returns the position of the filter, the detail and the actions of the status bar.
+func (c List) zones() (filter zone, detail zone, actions zone) {
+	filter = zone{x: 0, y: 2, width: c.filter.Width, height: c.filter.Height}
+	if c.showDetail {
+		// the detail is separated from the filter by a vertical bar
+		detail = zone{x: c.filter.Width + 1, y: 2, width: c.viewport.Width, height: c.viewport.Height}
 	}
+	actions = zone{x: 0, y: c.height - 1, width: c.width, height: 1}
 
-	return c, tea.Batch(cmds...)
+	return filter, detail, actions
+}
+
+// handleMouse returns the command triggered by a mouse event, and whether the event was handled.
+// Wheel events on the filter are left to the filter.
+func (c *List) handleMouse(msg tea.MouseMsg) (tea.Cmd, bool) {
+	filterZone, detailZone, actionsZone := c.zones()
+	switch {
+	case filterZone.contains(msg):
+		if c.statusBar.expanded {
+			return nil, true
+		}
+
+		if isWheel(msg) {
+			return nil, false
+		}
+
+		if !isClick(msg) {
+			return nil, true
+		}
+
+		_, row := filterZone.relative(msg)
+		index := c.filter.ItemAt(row)
+		if index == -1 {
+			return nil, true
+		}
+
+		filter := c.filter
+		filter.SetCursor(index)
+		c.setFilter(filter)
+
+		if !c.clicks.click(filter.Selection().ID()) || len(c.statusBar.actions) == 0 {
+			return nil, true
+		}
+
+		action := c.statusBar.actions[0]
+		return func() tea.Msg {
+			return action
+		}, true
+	case detailZone.contains(msg):
+		if !isWheel(msg) {
+			return nil, true
+		}
+
+		if msg.Button == tea.MouseButtonWheelUp {
+			c.viewport.LineUp(3)
+		} else {
+			c.viewport.LineDown(3)
+		}
+		return nil, true
+	case actionsZone.contains(msg):
+		if !isClick(msg) {
+			return nil, true
+		}
+
+		x, _ := actionsZone.relative(msg)
+		index, ok := c.statusBar.HitTest(x)
+		if !ok {
+			return nil, true
+		}
+
+		if index == -1 {
+			c.expandActions()
+			return nil, true
+		}
+
+		action := c.statusBar.filtered[index]
+		return func() tea.Msg {
+			return action
+		}, true
+	}
+
+	return nil, true
 }
 
 func (c List) View() string {
@@ -2,7 +2,9 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pomdtr/sunbeam/pkg/sunbeam"
@@ -19,11 +21,100 @@ func (i ListItem) ID() string {
 }
 
 func (i ListItem) FilterValue() string {
-	keywords := []string{i.Title, i.Subtitle}
-	return strings.Trim(strings.Join(keywords, " "), " ")
+	return i.filterValue([]string{"subtitle"})
 }
 
-func RenderItem(title string, subtitle string, accessories []string, width int, selected bool) string {
+// filterValue returns the text matched by the query: the title, the keywords and the given fields.
+// The title always comes first, followed by the subtitle when it is part of the fields, whatever their order:
+// the positions of the matches are mapped back to the title and the subtitle by highlights.
+func (i ListItem) filterValue(fields []string) string {
+	values := []string{i.Title}
+	if slices.Contains(fields, "subtitle") {
+		values = append(values, i.Subtitle)
+	}
+
+	for _, field := range fields {
+		if field == "accessories" {
+			values = append(values, i.Accessories...)
+		}
+	}
+	values = append(values, i.Keywords...)
+
+	value := values[0]
+	for _, v := range values[1:] {
+		if v != "" {
+			value += " " + v
+		}
+	}
+
+	return value
+}
+
+// highlights splits the positions matched in the filter value between the title and the subtitle.
+func (i ListItem) highlights(fields []string, matches []int) ([]int, []int) {
+	var titleMatches, subtitleMatches []int
+	titleLength := utf8.RuneCountInString(i.Title)
+	subtitleLength := 0
+	if i.Subtitle != "" && slices.Contains(fields, "subtitle") {
+		subtitleLength = utf8.RuneCountInString(i.Subtitle)
+	}
+
+	for _, pos := range matches {
+		if pos < titleLength {
+			titleMatches = append(titleMatches, pos)
+		} else if pos > titleLength && pos <= titleLength+subtitleLength {
+			subtitleMatches = append(subtitleMatches, pos-titleLength-1)
+		}
+	}
+
+	return titleMatches, subtitleMatches
+}
+
+// highlight renders the text with the given style, emphasizing the runes at the given positions.
+func highlight(text string, positions []int, style lipgloss.Style) string {
+	if len(positions) == 0 {
+		return style.Render(text)
+	}
+
+	matchStyle := style.Faint(false).Foreground(theme.Accent).Underline(true)
+	matched := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		matched[pos] = true
+	}
+
+	var builder strings.Builder
+	runes := []rune(text)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && matched[i] == matched[start] {
+			continue
+		}
+
+		if matched[start] {
+			builder.WriteString(matchStyle.Render(string(runes[start:i])))
+		} else {
+			builder.WriteString(style.Render(string(runes[start:i])))
+		}
+		start = i
+	}
+
+	return builder.String()
+}
+
+// shift offsets the positions, dropping the ones that are not part of the first n runes.
+func shift(positions []int, offset int, n int) []int {
+	res := make([]int, 0, len(positions))
+	for _, pos := range positions {
+		if pos+offset >= 0 && pos+offset < n {
+			res = append(res, pos+offset)
+		}
+	}
+
+	return res
+}
+
+// RenderItem renders a row of a list, the matches are the positions of the runes of the title and subtitle to highlight.
+func RenderItem(title string, subtitle string, accessories []string, width int, selected bool, titleMatches []int, subtitleMatches []int) string {
 	if width == 0 {
 		return ""
 	}
@@ -33,12 +124,12 @@ func RenderItem(title string, subtitle string, accessories []string, width int,
 	accessoryStyle := lipgloss.NewStyle()
 	if selected {
 		title = fmt.Sprintf("> %s", title)
-		titleStyle = titleStyle.Foreground(lipgloss.Color("13")).Bold(true)
-		accessoryStyle = accessoryStyle.Foreground(lipgloss.Color("13"))
-		subtitleStyle = subtitleStyle.Foreground(lipgloss.Color("13"))
+		titleStyle = theme.AccentStyle().Bold(true)
+		accessoryStyle = theme.AccentStyle()
+		subtitleStyle = theme.AccentStyle()
 	} else {
-		subtitleStyle = subtitleStyle.Faint(true)
-		accessoryStyle = accessoryStyle.Faint(true)
+		subtitleStyle = theme.FaintStyle()
+		accessoryStyle = theme.FaintStyle()
 		title = fmt.Sprintf("  %s", title)
 	}
 
@@ -49,27 +140,34 @@ func RenderItem(title string, subtitle string, accessories []string, width int,
 	var blanks string
 
 	// If the width is too small, we need to truncate the subtitle, title and accessory
+	// The runes are dropped from the end, so the matches of the remaining ones are preserved
 	for lipgloss.Width(title+subtitle+accessory) > width {
 		if words := strings.Split(subtitle, " "); len(words) > 1 {
 			subtitle = strings.Join(words[:len(words)-1], " ")
 		} else if len(accessory) > 0 {
-			accessory = accessory[:len(accessory)-1]
+			accessory = string([]rune(accessory)[:utf8.RuneCountInString(accessory)-1])
 		} else {
-			title = title[:len(title)-1]
+			title = string([]rune(title)[:utf8.RuneCountInString(title)-1])
 		}
 	}
 
 	extraWidth := width - lipgloss.Width(title+subtitle+accessory)
 	blanks = strings.Repeat(" ", extraWidth)
 
-	title = titleStyle.Render(title)
-	subtitle = subtitleStyle.Render(subtitle)
+	// the title is prefixed by the selection marker, and the subtitle by a space
+	title = highlight(title, shift(titleMatches, 2, utf8.RuneCountInString(title)), titleStyle)
+	subtitle = highlight(subtitle, shift(subtitleMatches, 1, utf8.RuneCountInString(subtitle)), subtitleStyle)
 	accessory = accessoryStyle.Render(accessory)
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, title, subtitle, blanks, accessory)
 
 }
 
-func (i ListItem) Render(width int, selected bool) string {
-	return RenderItem(i.Title, i.Subtitle, i.Accessories, width, selected)
+func (i ListItem) Render(width int, selected bool, matches []int) string {
+	return i.render(width, selected, []string{"subtitle"}, matches)
+}
+
+func (i ListItem) render(width int, selected bool, fields []string, matches []int) string {
+	titleMatches, subtitleMatches := i.highlights(fields, matches)
+	return RenderItem(i.Title, i.Subtitle, i.Accessories, width, selected, titleMatches, subtitleMatches)
 }
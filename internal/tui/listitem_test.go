@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestListItemHighlights(t *testing.T) {
+	item := ListItem{Title: "abc", Subtitle: "def", Accessories: []string{"ghi"}, Keywords: []string{"jkl"}}
+
+	testCases := []struct {
+		name            string
+		fields          []string
+		value           string
+		matches         []int
+		titleMatches    []int
+		subtitleMatches []int
+	}{
+		{
+			name:         "title only",
+			fields:       nil,
+			value:        "abc jkl",
+			matches:      []int{0, 4},
+			titleMatches: []int{0},
+		},
+		{
+			name:            "subtitle",
+			fields:          []string{"subtitle"},
+			value:           "abc def jkl",
+			matches:         []int{1, 5, 8},
+			titleMatches:    []int{1},
+			subtitleMatches: []int{1},
+		},
+		{
+			name:            "subtitle after accessories",
+			fields:          []string{"accessories", "subtitle"},
+			value:           "abc def ghi jkl",
+			matches:         []int{2, 6, 8},
+			titleMatches:    []int{2},
+			subtitleMatches: []int{2},
+		},
+		{
+			name:         "accessories only",
+			fields:       []string{"accessories"},
+			value:        "abc ghi jkl",
+			matches:      []int{0, 4},
+			titleMatches: []int{0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if value := item.filterValue(tc.fields); value != tc.value {
+				t.Fatalf("expected filter value %q, got %q", tc.value, value)
+			}
+
+			titleMatches, subtitleMatches := item.highlights(tc.fields, tc.matches)
+			if !reflect.DeepEqual(titleMatches, tc.titleMatches) || !reflect.DeepEqual(subtitleMatches, tc.subtitleMatches) {
+				t.Fatalf("expected matches %v and %v, got %v and %v", tc.titleMatches, tc.subtitleMatches, titleMatches, subtitleMatches)
+			}
+		})
+	}
+}
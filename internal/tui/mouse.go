@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mouseEnabled is set from the config when sunbeam starts, the mouse is disabled by default
+// to keep the native text selection of the terminal.
+var mouseEnabled bool
+
+func EnableMouse(enabled bool) {
+	mouseEnabled = enabled
+}
+
+// zone is the rectangle of the screen occupied by a component, pages compute their zones
+// from their layout to find the component under the mouse.
+type zone struct {
+	x, y          int
+	width, height int
+}
+
+func (z zone) contains(msg tea.MouseMsg) bool {
+	return msg.X >= z.x && msg.X < z.x+z.width && msg.Y >= z.y && msg.Y < z.y+z.height
+}
+
+// relative returns the position of the mouse inside the zone.
+func (z zone) relative(msg tea.MouseMsg) (int, int) {
+	return msg.X - z.x, msg.Y - z.y
+}
+
+const doubleClickInterval = 500 * time.Millisecond
+
+// clickTracker detects double clicks on the same target.
+type clickTracker struct {
+	target string
+	at     time.Time
+}
+
+// click records a click on the target, and reports whether it completes a double click.
+func (c *clickTracker) click(target string) bool {
+	now := time.Now()
+	double := c.target == target && now.Sub(c.at) < doubleClickInterval
+	if double {
+		c.target = ""
+	} else {
+		c.target = target
+	}
+	c.at = now
+
+	return double
+}
+
+func isClick(msg tea.MouseMsg) bool {
+	return msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft
+}
+
+func isWheel(msg tea.MouseMsg) bool {
+	return msg.Action == tea.MouseActionPress && (msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown)
+}
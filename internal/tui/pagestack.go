@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+)
+
+// PageStack lists the open pages, selecting one of them closes the pages opened after it.
+type PageStack struct {
+	list *List
+}
+
+func NewPageStack(titles []string) *PageStack {
+	// the most recent pages are listed first
+	var items []sunbeam.ListItem
+	for i := len(titles) - 1; i >= 0; i-- {
+		items = append(items, sunbeam.ListItem{
+			Id:          strconv.Itoa(i),
+			Title:       titles[i],
+			Accessories: []string{fmt.Sprintf("Level %d", i+1)},
+			Actions: []sunbeam.Action{
+				{Title: "Go To Page"},
+			},
+		})
+	}
+
+	list := NewList(items...)
+	list.SetEmptyText("No Pages")
+
+	return &PageStack{list: list}
+}
+
+func (c *PageStack) Title() string {
+	return "Pages"
+}
+
+func (c *PageStack) Init() tea.Cmd {
+	return c.list.Init()
+}
+
+func (c *PageStack) Focus() tea.Cmd {
+	return c.list.Focus()
+}
+
+func (c *PageStack) Blur() tea.Cmd {
+	return c.list.Blur()
+}
+
+func (c *PageStack) SetSize(width, height int) {
+	c.list.SetSize(width, height)
+}
+
+func (c *PageStack) Update(msg tea.Msg) (Page, tea.Cmd) {
+	if _, ok := msg.(sunbeam.Action); ok {
+		selection, ok := c.list.Selection()
+		if !ok {
+			return c, nil
+		}
+
+		index, err := strconv.Atoi(selection.Id)
+		if err != nil {
+			return c, nil
+		}
+
+		return c, func() tea.Msg {
+			return PopToPageMsg{Index: index}
+		}
+	}
+
+	page, cmd := c.list.Update(msg)
+	c.list = page.(*List)
+	return c, cmd
+}
+
+func (c *PageStack) View() string {
+	return c.list.View()
+}
@@ -1,7 +1,10 @@
 package tui
 
 import (
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 func PopPageCmd() tea.Msg {
@@ -33,6 +36,24 @@ type Page interface {
 	SetSize(width, height int)
 }
 
+// PopToPageMsg closes the pages opened after the page at the given index of the stack.
+type PopToPageMsg struct {
+	Index int
+}
+
+// TitledPage is implemented by the pages named in the breadcrumb and the page stack.
+type TitledPage interface {
+	Title() string
+}
+
+func PageTitle(page Page) string {
+	if titled, ok := page.(TitledPage); ok && titled.Title() != "" {
+		return titled.Title()
+	}
+
+	return "Untitled"
+}
+
 type ExitMsg struct {
 }
 
@@ -43,13 +64,25 @@ func ExitCmd() tea.Msg {
 type Paginator struct {
 	width, height int
 
-	pages  []Page
-	hidden bool
+	pages []Page
+	// pages restored below the current page, they are initialized when they are focused
+	lazy     map[Page]bool
+	hidden   bool
+	showHelp bool
 }
 
-func NewPaginator(root Page) *Paginator {
+// NewPaginator returns a paginator showing the last of the given pages, the pages below it
+// are initialized when they are focused.
+func NewPaginator(root Page, stacked ...Page) *Paginator {
+	pages := append([]Page{root}, stacked...)
+	lazy := make(map[Page]bool)
+	for _, page := range pages[:len(pages)-1] {
+		lazy[page] = true
+	}
+
 	return &Paginator{
-		pages: []Page{root},
+		pages: pages,
+		lazy:  lazy,
 	}
 }
 
@@ -58,23 +91,76 @@ func (m *Paginator) Init() tea.Cmd {
 		return nil
 	}
 
-	return m.pages[0].Init()
+	return m.pages[len(m.pages)-1].Init()
+}
+
+func (m *Paginator) Pages() []Page {
+	return m.pages
 }
 
 func (m *Paginator) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC:
+		switch {
+		case keymap.Matches(msg, KeyQuit):
 			m.hidden = true
 			return m, tea.Quit
+		case m.showHelp:
+			if keymap.Matches(msg, KeyBack) || keymap.Matches(msg, KeyHelp) {
+				m.showHelp = false
+			}
+			return m, nil
+		case keymap.Matches(msg, KeyHelp):
+			m.showHelp = true
+			return m, nil
+		case keymap.Matches(msg, KeyBackToRoot):
+			if len(m.pages) < 2 {
+				return m, nil
+			}
+
+			return m, m.PopTo(0)
+		case keymap.Matches(msg, KeyPageStack):
+			if _, ok := m.pages[len(m.pages)-1].(*PageStack); ok {
+				return m, nil
+			}
+
+			titles := make([]string, len(m.pages))
+			for i, page := range m.pages {
+				titles[i] = PageTitle(page)
+			}
+
+			return m, m.Push(NewPageStack(titles))
 		}
-	case tea.WindowSizeMsg:
-		if msg.Height%2 == 0 {
-			m.SetSize(msg.Width, msg.Height-1)
-		} else {
-			m.SetSize(msg.Width, msg.Height)
+	case ExtensionChangedMsg:
+		// the pages below the current one pick the change up now, but only reload when they are shown again,
+		// so that the messages of their reload are not delivered to the current page
+		for i, page := range m.pages[:max(len(m.pages)-1, 0)] {
+			m.pages[i], _ = page.Update(msg)
+			m.lazy[m.pages[i]] = true
+		}
+	case tea.MouseMsg:
+		if m.showHelp {
+			return m, nil
+		}
+
+		if m.headerHeight() > 0 {
+			if msg.Y < m.headerHeight() {
+				if !isClick(msg) {
+					return m, nil
+				}
+
+				if index, ok := m.breadcrumbAt(msg.X); ok && index < len(m.pages)-1 {
+					return m, m.PopTo(index)
+				}
+				return m, nil
+			}
+
+			// pages are positioned below the breadcrumb
+			msg.Y -= m.headerHeight()
+			return m, m.updatePage(msg)
 		}
+	case tea.WindowSizeMsg:
+		m.SetSize(msg.Width, msg.Height)
 		return m, nil
 	case PushPageMsg:
 		cmd := m.Push(msg.Page)
@@ -86,22 +172,26 @@ func (m *Paginator) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.hidden = true
 		return m, tea.Quit
+	case PopToPageMsg:
+		return m, m.PopTo(msg.Index)
 	case ExitMsg:
 		m.hidden = true
 		return m, tea.Quit
 	}
 
-	// Update the current page
-	var cmd tea.Cmd
+	return m, m.updatePage(msg)
+}
 
-	if len(m.pages) > 0 {
-		currentPageIdx := len(m.pages) - 1
-		m.pages[currentPageIdx], cmd = m.pages[currentPageIdx].Update(msg)
-	} else {
-		return m, nil
+// updatePage forwards the message to the current page.
+func (m *Paginator) updatePage(msg tea.Msg) tea.Cmd {
+	if len(m.pages) == 0 {
+		return nil
 	}
 
-	return m, cmd
+	var cmd tea.Cmd
+	currentPageIdx := len(m.pages) - 1
+	m.pages[currentPageIdx], cmd = m.pages[currentPageIdx].Update(msg)
+	return cmd
 }
 
 func (m *Paginator) View() string {
@@ -109,51 +199,183 @@ func (m *Paginator) View() string {
 		return ""
 	}
 
-	if len(m.pages) > 0 {
-		currentPage := m.pages[len(m.pages)-1]
+	if m.showHelp {
+		return keymap.View(m.width, m.height)
+	}
+
+	if len(m.pages) == 0 {
+		return ""
+	}
+
+	currentPage := m.pages[len(m.pages)-1]
+	if m.headerHeight() == 0 {
 		return currentPage.View()
 	}
 
-	return ""
+	breadcrumb, _ := m.breadcrumb()
+	return lipgloss.JoinVertical(lipgloss.Left, breadcrumb, currentPage.View())
+}
+
+// headerHeight is the height of the breadcrumb, it is only shown when a page is stacked on the root page.
+func (m *Paginator) headerHeight() int {
+	if len(m.pages) < 2 {
+		return 0
+	}
+
+	return 1
+}
+
+// breadcrumbSegment is the span of the title of a page in the breadcrumb.
+type breadcrumbSegment struct {
+	start, end int
+	index      int
+}
+
+// breadcrumb renders the titles of the pages of the stack, the first pages are elided if the
+// breadcrumb does not fit in the width of the window.
+func (m *Paginator) breadcrumb() (string, []breadcrumbSegment) {
+	const delimiter = " › "
+	const ellipsis = "…"
+
+	titles := make([]string, len(m.pages))
+	for i, page := range m.pages {
+		titles[i] = PageTitle(page)
+	}
+
+	startIdx := 0
+	for startIdx < len(titles)-1 {
+		width := 1 + lipgloss.Width(strings.Join(titles[startIdx:], delimiter))
+		if startIdx > 0 {
+			width += lipgloss.Width(ellipsis + delimiter)
+		}
+		if width <= m.width {
+			break
+		}
+		startIdx++
+	}
+
+	var parts []string
+	var segments []breadcrumbSegment
+	offset := 1
+	if startIdx > 0 {
+		parts = append(parts, theme.FaintStyle().Render(ellipsis))
+		offset += lipgloss.Width(ellipsis + delimiter)
+	}
+
+	for i := startIdx; i < len(titles); i++ {
+		width := lipgloss.Width(titles[i])
+		segments = append(segments, breadcrumbSegment{start: offset, end: offset + width, index: i})
+		offset += width + lipgloss.Width(delimiter)
+
+		if i == len(titles)-1 {
+			parts = append(parts, theme.AccentStyle().Bold(true).Render(titles[i]))
+		} else {
+			parts = append(parts, theme.FaintStyle().Render(titles[i]))
+		}
+	}
+
+	breadcrumb := " " + strings.Join(parts, theme.FaintStyle().Render(delimiter))
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(breadcrumb), segments
+}
+
+// breadcrumbAt returns the index of the page whose title is at the given column of the breadcrumb.
+func (m *Paginator) breadcrumbAt(x int) (int, bool) {
+	_, segments := m.breadcrumb()
+	for _, segment := range segments {
+		if x >= segment.start && x < segment.end {
+			return segment.index, true
+		}
+	}
+
+	return 0, false
 }
 
 func (m *Paginator) SetSize(width, height int) {
 	m.width = width
 	m.height = height
 
+	pageHeight := m.pageHeight()
 	for _, page := range m.pages {
-		page.SetSize(m.width, m.height)
+		page.SetSize(m.width, pageHeight)
 	}
 }
 
+// pageHeight is the height left to the pages below the breadcrumb. It is kept odd, so that
+// the lists end with an item rather than a separator.
+func (m *Paginator) pageHeight() int {
+	height := m.height - m.headerHeight()
+	if height%2 == 0 {
+		height--
+	}
+
+	return max(height, 0)
+}
+
 func (m *Paginator) Push(page Page) tea.Cmd {
 	var cmd tea.Cmd
 	if len(m.pages) > 0 {
 		cmd = m.pages[len(m.pages)-1].Blur()
 	}
-	page.SetSize(m.width, m.height)
 	m.pages = append(m.pages, page)
+	// the breadcrumb may have appeared, so every page is resized
+	m.SetSize(m.width, m.height)
 	return tea.Sequence(cmd, page.Init())
 }
 
 func (m *Paginator) Pop() tea.Cmd {
-	var cmds []tea.Cmd
-	if len(m.pages) > 0 {
-		cmds = append(cmds, m.pages[len(m.pages)-1].Blur())
-		m.pages = m.pages[:len(m.pages)-1]
+	return m.PopTo(len(m.pages) - 2)
+}
+
+// PopTo closes the pages opened after the page at the given index, and focuses it.
+func (m *Paginator) PopTo(index int) tea.Cmd {
+	if index < 0 || index >= len(m.pages)-1 {
+		return nil
 	}
 
-	if len(m.pages) > 0 {
-		cmds = append(cmds, m.pages[len(m.pages)-1].Focus())
+	cmds := []tea.Cmd{m.pages[len(m.pages)-1].Blur()}
+	for _, page := range m.pages[index+1:] {
+		delete(m.lazy, page)
+	}
+	m.pages = m.pages[:index+1]
+	m.SetSize(m.width, m.height)
+
+	if page := m.pages[index]; m.lazy[page] {
+		delete(m.lazy, page)
+		cmds = append(cmds, page.Init())
+	} else {
+		cmds = append(cmds, page.Focus())
 	}
 
 	return tea.Sequence(cmds...)
 }
 
-func Draw(page Page) error {
-	paginator := NewPaginator(page)
-	p := tea.NewProgram(paginator, tea.WithAltScreen())
+func NewProgram(page Page, stacked ...Page) *tea.Program {
+	paginator := NewPaginator(page, stacked...)
+	options := []tea.ProgramOption{tea.WithAltScreen()}
+	if mouseEnabled {
+		options = append(options, tea.WithMouseCellMotion())
+	}
 
-	_, err := p.Run()
+	return tea.NewProgram(paginator, options...)
+}
+
+// Draw runs the interface, starting with the stacked pages on top of the root page.
+func Draw(page Page, stacked ...Page) error {
+	_, err := Run(page, stacked...)
 	return err
 }
+
+// Run is the same as Draw, it also returns the pages open when the interface exited.
+func Run(page Page, stacked ...Page) ([]Page, error) {
+	model, err := NewProgram(page, stacked...).Run()
+	if err != nil {
+		return nil, err
+	}
+
+	paginator, ok := model.(*Paginator)
+	if !ok {
+		return nil, nil
+	}
+
+	return paginator.Pages(), nil
+}
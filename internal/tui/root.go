@@ -2,11 +2,8 @@ package tui
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"sort"
 	"strings"
 
@@ -28,13 +25,17 @@ type RootList struct {
 	form          *Form
 
 	config    config.Config
-	history   history.History
+	history   history.Store
 	generator func() (config.Config, []sunbeam.ListItem, error)
+
+	// the other listing of the root items, swapped with the current one by the toggle-palette key
+	alternateTitle     string
+	alternateGenerator func() (config.Config, []sunbeam.ListItem, error)
 }
 
 type ReloadMsg struct{}
 
-func NewRootList(title string, history history.History, generator func() (config.Config, []sunbeam.ListItem, error)) *RootList {
+func NewRootList(title string, history history.Store, generator func() (config.Config, []sunbeam.ListItem, error)) *RootList {
 	return &RootList{
 		title:     title,
 		history:   history,
@@ -42,6 +43,12 @@ func NewRootList(title string, history history.History, generator func() (config
 	}
 }
 
+// SetAlternate sets the listing the root list switches to with the toggle-palette key, like the command palette.
+func (c *RootList) SetAlternate(title string, generator func() (config.Config, []sunbeam.ListItem, error)) {
+	c.alternateTitle = title
+	c.alternateGenerator = generator
+}
+
 func (c *RootList) Init() tea.Cmd {
 	termenv.DefaultOutput().SetWindowTitle(c.title)
 	return c.Reload()
@@ -53,14 +60,24 @@ func (c *RootList) Reload() tea.Cmd {
 		return c.SetError(err)
 	}
 
+	if err := c.history.Load(); err != nil {
+		return c.SetError(err)
+	}
+
+	c.err = nil
 	c.config = cfg
-	c.history.Sort(rootItems)
+	sortByRank(rootItems, func(item sunbeam.ListItem) float64 {
+		return c.history.Rank(item.Id, "")
+	})
 	if c.list != nil {
 		c.list.SetIsLoading(false)
 		c.list.SetItems(rootItems...)
 		return nil
 	} else {
 		c.list = NewList(rootItems...)
+		c.list.filter.Rank = func(item FilterItem, query string) float64 {
+			return c.history.Rank(item.ID(), query)
+		}
 		c.list.SetEmptyText("No items")
 		c.list.SetSize(c.width, c.height)
 
@@ -73,6 +90,10 @@ func (c *RootList) Focus() tea.Cmd {
 	return c.list.Focus()
 }
 
+func (c *RootList) Title() string {
+	return c.title
+}
+
 func (c *RootList) Blur() tea.Cmd {
 	return c.list.SetIsLoading(false)
 }
@@ -102,13 +123,13 @@ func (c *RootList) SetError(err error) tea.Cmd {
 func (c *RootList) Update(msg tea.Msg) (Page, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case keymap.Matches(msg, KeyBack):
 			if c.form != nil {
 				c.form = nil
 				return c, c.list.Focus()
 			}
-		case "ctrl+s":
+		case keymap.Matches(msg, KeyEdit):
 			if c.form != nil {
 				break
 			}
@@ -123,41 +144,52 @@ func (c *RootList) Update(msg tea.Msg) (Page, tea.Cmd) {
 
 				return ReloadMsg{}
 			})
-		case "ctrl+r":
+		case keymap.Matches(msg, KeyReload):
+			return c, tea.Batch(c.list.SetIsLoading(true), c.Reload())
+		case keymap.Matches(msg, KeyTogglePalette):
+			if c.form != nil || c.alternateGenerator == nil {
+				break
+			}
+
+			c.title, c.alternateTitle = c.alternateTitle, c.title
+			c.generator, c.alternateGenerator = c.alternateGenerator, c.generator
+			termenv.DefaultOutput().SetWindowTitle(c.title)
 			return c, tea.Batch(c.list.SetIsLoading(true), c.Reload())
 		}
 	case ReloadMsg:
 		return c, tea.Batch(c.list.SetIsLoading(true), c.Reload())
+	case ExtensionChangedMsg:
+		if msg.Err != nil {
+			return c, c.SetError(msg.Err)
+		}
+
+		return c, c.Reload()
 	case sunbeam.Action:
 		selection, ok := c.list.Selection()
 		if !ok {
 			return c, nil
 		}
-		c.history.Update(selection.Id)
+		c.history.Record(selection.Id, c.list.Query())
 		if err := c.history.Save(); err != nil {
 			return c, c.SetError(err)
 		}
 
 		switch msg.Type {
 		case sunbeam.ActionTypeRun:
-			extensionConfig := c.config.Extensions[msg.Run.Extension]
-			extension, err := extensions.LoadExtension(extensionConfig.Origin)
-			if err != nil {
-				return c, c.SetError(fmt.Errorf("failed to load extension: %w", err))
+			extensionConfig, ok := c.config.Extensions[msg.Run.Extension]
+			if !ok {
+				return c, c.SetError(fmt.Errorf("extension %s is not installed", msg.Run.Extension))
 			}
 
-			preferences := extensionConfig.Preferences
-			if preferences == nil {
-				preferences = make(map[string]any)
+			extension, err := extensions.LoadExtension(extensionConfig)
+			if err != nil {
+				return c, c.SetError(fmt.Errorf("failed to load extension: %w", err))
 			}
 
-			envs, err := ExtractPreferencesFromEnv(msg.Run.Extension, extension)
+			preferences, err := LoadPreferences(msg.Run.Extension, extension)
 			if err != nil {
 				return c, c.SetError(err)
 			}
-			for name, value := range envs {
-				preferences[name] = value
-			}
 
 			missingPreferences := FindMissingPreferences(extension.Manifest.Preferences, preferences)
 			for _, preference := range missingPreferences {
@@ -166,20 +198,7 @@ func (c *RootList) Update(msg tea.Msg) (Page, tea.Cmd) {
 				}
 
 				c.form = NewForm(func(values map[string]any) tea.Msg {
-					if extensionConfig.Preferences == nil {
-						extensionConfig.Preferences = make(map[string]any)
-					}
-
-					for k, v := range values {
-						extensionConfig.Preferences[k] = v
-					}
-
-					c.config.Extensions[msg.Run.Extension] = extensionConfig
-					if config.Path == "" {
-						return msg
-					}
-
-					if err := c.config.Save(); err != nil {
+					if err := SavePreferences(c.config, msg.Run.Extension, extension, values); err != nil {
 						return err
 					}
 
@@ -237,7 +256,7 @@ func (c *RootList) Update(msg tea.Msg) (Page, tea.Cmd) {
 
 			switch command.Mode {
 			case sunbeam.CommandModeSearch, sunbeam.CommandModeFilter, sunbeam.CommandModeDetail:
-				runner := NewRunner(extension, input)
+				runner := NewRunner(msg.Run.Extension, extension, input)
 				return c, PushPageCmd(runner)
 			case sunbeam.CommandModeSilent:
 				return c, func() tea.Msg {
@@ -269,7 +288,7 @@ func (c *RootList) Update(msg tea.Msg) (Page, tea.Cmd) {
 
 				return c, tea.ExecProcess(cmd, func(err error) tea.Msg {
 					if err != nil {
-						return PushPageMsg{NewErrorPage(err)}
+						return PushPageMsg{NewErrorPage(extension.LogFailure(input, err))}
 					}
 
 					if msg.Run.Exit {
@@ -315,7 +334,7 @@ func (c *RootList) Update(msg tea.Msg) (Page, tea.Cmd) {
 				return c, c.SetError(fmt.Errorf("extension %s not found", msg.Config.Extension))
 			}
 
-			extension, err := extensions.LoadExtension(extensionConfig.Origin)
+			extension, err := extensions.LoadExtension(extensionConfig)
 			if err != nil {
 				return c, c.SetError(fmt.Errorf("failed to load extension %s", msg.Config.Extension))
 			}
@@ -331,9 +350,7 @@ func (c *RootList) Update(msg tea.Msg) (Page, tea.Cmd) {
 
 			c.form = NewForm(func(values map[string]any) tea.Msg {
 				c.form = nil
-				extensionConfig.Preferences = values
-				c.config.Extensions[msg.Config.Extension] = extensionConfig
-				if err := c.config.Save(); err != nil {
+				if err := SavePreferences(c.config, msg.Config.Extension, extension, values); err != nil {
 					return err
 				}
 
@@ -342,56 +359,7 @@ func (c *RootList) Update(msg tea.Msg) (Page, tea.Cmd) {
 			c.form.SetSize(c.width, c.height)
 			return c, c.form.Init()
 		case sunbeam.ActionTypeExec:
-			cmd := exec.Command("sh", "-c", msg.Exec.Command)
-			cmd.Dir = msg.Exec.Dir
-			if strings.HasPrefix(cmd.Dir, "~") {
-				homeDir, err := os.UserHomeDir()
-				if err != nil {
-					return c, c.SetError(err)
-				}
-
-				cmd.Dir = filepath.Join(homeDir, strings.TrimPrefix(cmd.Dir, "~"))
-			}
-
-			if !filepath.IsAbs(cmd.Dir) {
-				wd, err := os.Getwd()
-				if err != nil {
-					return c, c.SetError(err)
-				}
-
-				cmd.Dir = filepath.Join(wd, cmd.Dir)
-			}
-
-			if !msg.Exec.Interactive {
-				return c, func() tea.Msg {
-					output, err := cmd.Output()
-					if err != nil {
-						return err
-					}
-
-					if msg.Exec.Exit {
-						return ExitMsg{}
-					}
-
-					if len(output) > 0 {
-						output = bytes.Trim(output, "\n")
-						rows := strings.Split(string(output), "\n")
-						return ShowNotificationMsg{rows[len(rows)-1]}
-					}
-
-					return nil
-				}
-			}
-
-			return c, tea.ExecProcess(cmd, func(err error) tea.Msg {
-				if err != nil {
-					return err
-				}
-
-				if msg.Exec.Exit {
-					return ExitMsg{}
-				}
-
+			return c, ExecCmd(msg.Exec, func() tea.Msg {
 				termenv.DefaultOutput().SetWindowTitle(c.title)
 				return nil
 			})
@@ -462,58 +430,9 @@ func (c *RootList) View() string {
 	return ""
 }
 
-type History struct {
-	entries map[string]int64
-	path    string
-}
-
-func (h History) Sort(items []sunbeam.ListItem) {
+// sortByRank orders the items by decreasing rank, keeping the original order for items with the same rank.
+func sortByRank(items []sunbeam.ListItem, rank func(sunbeam.ListItem) float64) {
 	sort.SliceStable(items, func(i, j int) bool {
-		keyI := items[i].Id
-		keyJ := items[j].Id
-
-		return h.entries[keyI] > h.entries[keyJ]
+		return rank(items[i]) > rank(items[j])
 	})
 }
-
-func LoadHistory(fp string) (History, error) {
-	f, err := os.Open(fp)
-	if err != nil {
-		return History{}, err
-	}
-
-	var entries map[string]int64
-	if err := json.NewDecoder(f).Decode(&entries); err != nil {
-		return History{}, err
-	}
-
-	return History{
-		entries: entries,
-		path:    fp,
-	}, nil
-}
-
-func (h History) Save() error {
-	f, err := os.OpenFile(h.path, os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return err
-		}
-
-		if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
-			return err
-		}
-
-		f, err = os.Create(h.path)
-		if err != nil {
-			return err
-		}
-	}
-
-	encoder := json.NewEncoder(f)
-	if err := encoder.Encode(h.entries); err != nil {
-		return err
-	}
-
-	return nil
-}
@@ -6,12 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"os/exec"
+	"reflect"
+	"slices"
 
-	"github.com/acarl005/stripansi"
 	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/muesli/termenv"
+	"github.com/pomdtr/sunbeam/internal/config"
 	"github.com/pomdtr/sunbeam/internal/extensions"
+	"github.com/pomdtr/sunbeam/internal/history"
 	"github.com/pomdtr/sunbeam/internal/schemas"
 	"github.com/pomdtr/sunbeam/internal/utils"
 	"github.com/pomdtr/sunbeam/pkg/sunbeam"
@@ -23,12 +26,37 @@ type Runner struct {
 	width, height int
 	cancel        context.CancelFunc
 
+	alias     string
 	extension extensions.Extension
 	command   sunbeam.CommandSpec
 	input     sunbeam.Payload
+
+	history           history.Store
+	rememberSelection bool
+}
+
+// ExtensionChangedMsg is sent when the source of an extension changed on disk.
+type ExtensionChangedMsg struct {
+	Extension extensions.Extension
+	Err       error
+}
+
+// listLoadedMsg is sent when the output of a list command is parsed, along with the history
+// used to rank its items if the command remembers the selection.
+type listLoadedMsg struct {
+	list    sunbeam.List
+	history history.Store
+}
+
+// extensionLoadedMsg is sent when the extension targeted by a run action is loaded.
+type extensionLoadedMsg struct {
+	action      sunbeam.Action
+	config      config.Config
+	extension   extensions.Extension
+	preferences map[string]any
 }
 
-func NewRunner(extension extensions.Extension, input sunbeam.Payload) *Runner {
+func NewRunner(alias string, extension extensions.Extension, input sunbeam.Payload) *Runner {
 	var embed Page
 	command, ok := extension.Command(input.Command)
 	if ok {
@@ -52,6 +80,7 @@ func NewRunner(extension extensions.Extension, input sunbeam.Payload) *Runner {
 
 	return &Runner{
 		embed:     embed,
+		alias:     alias,
 		extension: extension,
 		command:   command,
 		input:     input,
@@ -82,8 +111,18 @@ func (c *Runner) Focus() tea.Cmd {
 	return c.embed.Focus()
 }
 
+func (c *Runner) Title() string {
+	if c.command.Title != "" {
+		return c.command.Title
+	}
+
+	return c.input.Command
+}
+
 func (c *Runner) Blur() tea.Cmd {
-	c.cancel()
+	if c.cancel != nil {
+		c.cancel()
+	}
 	return nil
 }
 
@@ -101,8 +140,8 @@ func (c *Runner) SetSize(w int, h int) {
 func (c *Runner) Update(msg tea.Msg) (Page, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case keymap.Matches(msg, KeyBack):
 			if c.form != nil {
 				c.form = nil
 				return c, c.embed.Focus()
@@ -112,14 +151,14 @@ func (c *Runner) Update(msg tea.Msg) (Page, tea.Cmd) {
 				break
 			}
 			return c, PopPageCmd
-		case "ctrl+s":
+		case keymap.Matches(msg, KeyEdit):
 			editCmd := exec.Command("sunbeam", "edit", c.extension.Entrypoint)
 			return c, tea.ExecProcess(editCmd, func(err error) tea.Msg {
 				if err != nil {
 					return err
 				}
 
-				extension, err := extensions.LoadExtension(c.extension.Entrypoint)
+				extension, err := extensions.LoadExtension(c.extension.Config)
 				if err != nil {
 					return err
 				}
@@ -127,9 +166,9 @@ func (c *Runner) Update(msg tea.Msg) (Page, tea.Cmd) {
 
 				return ReloadMsg{}
 			})
-		case "ctrl+r":
+		case keymap.Matches(msg, KeyReload):
 			return c, func() tea.Msg {
-				manifest, err := extensions.ExtractManifest(c.extension.Entrypoint)
+				manifest, err := extensions.ExtractManifest(c.extension.Entrypoint, extensions.ManifestTimeout(c.extension.Config))
 				if err != nil {
 					return err
 				}
@@ -140,188 +179,291 @@ func (c *Runner) Update(msg tea.Msg) (Page, tea.Cmd) {
 		}
 	case ReloadMsg:
 		return c, c.Reload()
+	case ExtensionChangedMsg:
+		if msg.Extension.Entrypoint != c.extension.Entrypoint {
+			return c, nil
+		}
+
+		if msg.Err != nil {
+			return c, func() tea.Msg { return msg.Err }
+		}
+
+		command, ok := msg.Extension.Command(c.input.Command)
+		if !ok {
+			return c, func() tea.Msg { return fmt.Errorf("command %s not found", c.input.Command) }
+		}
+
+		c.extension = msg.Extension
+		c.command = command
+		return c, c.Reload()
 	case Page:
 		c.embed = msg
 		c.embed.SetSize(c.width, c.height)
 		return c, c.embed.Init()
 	case sunbeam.Action:
-		switch msg.Type {
-		case sunbeam.ActionTypeRun:
-			command, ok := c.extension.Command(msg.Run.Command)
-			if !ok {
-				c.embed = NewErrorPage(fmt.Errorf("command %s not found", msg.Run.Command))
-				c.embed.SetSize(c.width, c.height)
-				return c, c.embed.Init()
+		return c, tea.Batch(c.recordSelection(msg), c.runAction(msg))
+	case listLoadedMsg:
+		return c, c.setList(msg)
+	case extensionLoadedMsg:
+		cmd := c.SetIsLoading(false)
+		missingPreferences := FindMissingPreferences(msg.extension.Manifest.Preferences, msg.preferences)
+		for _, preference := range missingPreferences {
+			if preference.Optional {
+				continue
 			}
 
-			missing := FindMissingInputs(command.Params, msg.Run.Params)
-			for _, param := range missing {
-				if param.Optional {
-					continue
+			c.form = NewForm(func(values map[string]any) tea.Msg {
+				if err := SavePreferences(msg.config, msg.action.Run.Extension, msg.extension, values); err != nil {
+					return err
 				}
 
-				c.form = NewForm(func(values map[string]any) tea.Msg {
-					params := make(map[string]any)
-					for k, v := range msg.Run.Params {
-						params[k] = v
-					}
-
-					for k, v := range values {
-						params[k] = v
-					}
-
-					props := msg.Run
-					props.Params = params
-
-					return sunbeam.Action{
-						Title:  msg.Title,
-						Type:   sunbeam.ActionTypeRun,
-						Run:    props,
-						Reload: msg.Reload,
-					}
-				}, missing...)
-
-				c.form.SetSize(c.width, c.height)
-				return c, tea.Sequence(c.form.Init(), c.form.Focus())
-			}
-			c.form = nil
-
-			input := sunbeam.Payload{
-				Command:     msg.Run.Command,
-				Preferences: c.input.Preferences,
-				Params:      make(map[string]any),
-			}
+				return msg.action
+			}, missingPreferences...)
 
-			for k, v := range msg.Run.Params {
-				input.Params[k] = v
-			}
+			c.form.SetSize(c.width, c.height)
+			return c, tea.Batch(cmd, tea.Sequence(c.form.Init(), c.form.Focus()))
+		}
 
-			switch command.Mode {
-			case sunbeam.CommandModeSearch, sunbeam.CommandModeFilter, sunbeam.CommandModeDetail:
-				runner := NewRunner(c.extension, input)
+		return c, tea.Batch(cmd, c.runCommand(msg.action, msg.action.Run.Extension, msg.extension, msg.preferences))
+	case error:
+		var actions []sunbeam.Action
+		var timeoutErr extensions.TimeoutError
+		if errors.As(msg, &timeoutErr) {
+			actions = append(actions, sunbeam.Action{
+				Title:  "Retry",
+				Type:   sunbeam.ActionTypeReload,
+				Reload: &sunbeam.ReloadAction{},
+			})
+		}
 
-				return c, PushPageCmd(runner)
-			case sunbeam.CommandModeSilent:
-				return c, func() tea.Msg {
-					_, err := c.extension.Output(input)
+		c.embed = NewErrorPage(msg, actions...)
+		c.embed.SetSize(c.width, c.height)
+		return c, c.embed.Init()
+	}
 
-					if err != nil {
-						return PushPageMsg{NewErrorPage(err)}
-					}
+	if c.form != nil {
+		form, cmd := c.form.Update(msg)
+		c.form = form.(*Form)
+		return c, cmd
+	}
 
-					if msg.Run.Reload {
-						return ReloadMsg{}
-					}
+	var cmd tea.Cmd
+	c.embed, cmd = c.embed.Update(msg)
+	return c, cmd
+}
 
-					if msg.Run.Exit {
-						return ExitMsg{}
-					}
+func (c *Runner) runAction(msg sunbeam.Action) tea.Cmd {
+	switch msg.Type {
+	case sunbeam.ActionTypeRun:
+		if msg.Run.Extension != "" {
+			// loading the config and the manifest can take a while, the page shows a spinner meanwhile
+			return tea.Batch(c.SetIsLoading(true), loadExtensionCmd(msg))
+		}
 
-					return nil
-				}
-			case sunbeam.CommandModeTTY:
-				cmd, err := c.extension.Cmd(input)
-				if err != nil {
-					c.embed = NewErrorPage(err)
-					c.embed.SetSize(c.width, c.height)
-					return c, c.embed.Init()
-				}
+		return c.runCommand(msg, c.alias, c.extension, c.input.Preferences)
+	case sunbeam.ActionTypeEdit:
+		editCmd := exec.Command("sunbeam", "edit", msg.Edit.Path)
+		return tea.ExecProcess(editCmd, func(err error) tea.Msg {
+			if err != nil {
+				return err
+			}
 
-				return c, tea.ExecProcess(cmd, func(err error) tea.Msg {
-					if err != nil {
-						return PushPageMsg{NewErrorPage(err)}
-					}
+			if msg.Edit.Reload {
+				c.embed.Focus()
+				return c.Reload()
+			}
 
-					if msg.Run.Reload {
-						c.embed.Focus()
-						return ReloadMsg{}
-					}
+			if msg.Edit.Exit {
+				return ExitMsg{}
+			}
 
-					if msg.Run.Exit {
-						return ExitMsg{}
-					}
+			return c.embed.Focus()
+		})
+	case sunbeam.ActionTypeExec:
+		return ExecCmd(msg.Exec, func() tea.Msg {
+			termenv.DefaultOutput().SetWindowTitle(fmt.Sprintf("%s - %s", c.command.Title, c.extension.Manifest.Title))
+			return c.embed.Focus()
+		})
+	case sunbeam.ActionTypeCopy:
+		return func() tea.Msg {
+			if err := clipboard.WriteAll(msg.Copy.Text); err != nil {
+				return err
+			}
 
-					termenv.DefaultOutput().SetWindowTitle(fmt.Sprintf("%s - %s", c.command.Title, c.extension.Manifest.Title))
-					return c.embed.Focus()
-				})
+			if msg.Copy.Exit {
+				return ExitMsg{}
 			}
-		case sunbeam.ActionTypeEdit:
-			editCmd := exec.Command("sunbeam", "edit", msg.Edit.Path)
-			return c, tea.ExecProcess(editCmd, func(err error) tea.Msg {
-				if err != nil {
+
+			return ShowNotificationMsg{"Copied!"}
+		}
+	case sunbeam.ActionTypeOpen:
+		return func() tea.Msg {
+			if msg.Open.Url != "" {
+				if err := utils.Open(msg.Open.Url); err != nil {
 					return err
 				}
 
-				if msg.Edit.Reload {
-					c.embed.Focus()
-					return c.Reload()
+				return ExitMsg{}
+			} else if msg.Open.Path != "" {
+				if err := utils.Open(fmt.Sprintf("file://%s", msg.Open.Path)); err != nil {
+					return err
 				}
 
-				if msg.Edit.Exit {
-					return ExitMsg{}
-				}
+				return ExitMsg{}
+			} else {
+				return fmt.Errorf("invalid target")
+			}
+		}
+	case sunbeam.ActionTypeExit:
+		return ExitCmd
+	case sunbeam.ActionTypeReload:
+		if c.input.Params == nil {
+			c.input.Params = make(map[string]any)
+		}
 
-				return c.embed.Focus()
-			})
-		case sunbeam.ActionTypeCopy:
-			return c, func() tea.Msg {
-				if err := clipboard.WriteAll(msg.Copy.Text); err != nil {
-					return err
-				}
+		for k, v := range msg.Reload.Params {
+			c.input.Params[k] = v
+		}
 
-				if msg.Copy.Exit {
-					return ExitMsg{}
-				}
+		return c.Reload()
+	}
+	return nil
+}
+
+// loadExtensionCmd loads the extension targeted by a run action, along with its preferences.
+func loadExtensionCmd(msg sunbeam.Action) tea.Cmd {
+	return func() tea.Msg {
+		cfg, err := config.Load(config.Path)
+		if err != nil {
+			return err
+		}
+
+		extensionConfig, ok := cfg.Extensions[msg.Run.Extension]
+		if !ok {
+			return fmt.Errorf("extension %s is not installed, install it with sunbeam extension install", msg.Run.Extension)
+		}
+
+		extension, err := extensions.LoadExtension(extensionConfig)
+		if err != nil {
+			return fmt.Errorf("failed to load extension %s: %w", msg.Run.Extension, err)
+		}
+
+		preferences, err := LoadPreferences(msg.Run.Extension, extension)
+		if err != nil {
+			return err
+		}
+
+		return extensionLoadedMsg{
+			action:      msg,
+			config:      cfg,
+			extension:   extension,
+			preferences: preferences,
+		}
+	}
+}
+
+// runCommand runs the command of a run action once its extension is loaded.
+func (c *Runner) runCommand(msg sunbeam.Action, alias string, extension extensions.Extension, preferences map[string]any) tea.Cmd {
+	command, ok := extension.Command(msg.Run.Command)
+	if !ok {
+		c.embed = NewErrorPage(fmt.Errorf("command %s not found", msg.Run.Command))
+		c.embed.SetSize(c.width, c.height)
+		return c.embed.Init()
+	}
+
+	missing := FindMissingInputs(command.Params, msg.Run.Params)
+	for _, param := range missing {
+		if param.Optional {
+			continue
+		}
 
-				return ShowNotificationMsg{"Copied!"}
+		c.form = NewForm(func(values map[string]any) tea.Msg {
+			params := make(map[string]any)
+			for k, v := range msg.Run.Params {
+				params[k] = v
 			}
-		case sunbeam.ActionTypeOpen:
-			return c, func() tea.Msg {
-				if msg.Open.Url != "" {
-					if err := utils.Open(msg.Open.Url); err != nil {
-						return err
-					}
-
-					return ExitMsg{}
-				} else if msg.Open.Path != "" {
-					if err := utils.Open(fmt.Sprintf("file://%s", msg.Open.Path)); err != nil {
-						return err
-					}
-
-					return ExitMsg{}
-				} else {
-					return fmt.Errorf("invalid target")
-				}
+
+			for k, v := range values {
+				params[k] = v
 			}
-		case sunbeam.ActionTypeExit:
-			return c, ExitCmd
-		case sunbeam.ActionTypeReload:
-			if c.input.Params == nil {
-				c.input.Params = make(map[string]any)
+
+			props := msg.Run
+			props.Params = params
+
+			return sunbeam.Action{
+				Title:  msg.Title,
+				Type:   sunbeam.ActionTypeRun,
+				Run:    props,
+				Reload: msg.Reload,
 			}
+		}, missing...)
+
+		c.form.SetSize(c.width, c.height)
+		return tea.Sequence(c.form.Init(), c.form.Focus())
+	}
+	c.form = nil
+
+	input := sunbeam.Payload{
+		Command:     msg.Run.Command,
+		Preferences: preferences,
+		Params:      make(map[string]any),
+	}
+
+	for k, v := range msg.Run.Params {
+		input.Params[k] = v
+	}
 
-			for k, v := range msg.Reload.Params {
-				c.input.Params[k] = v
+	switch command.Mode {
+	case sunbeam.CommandModeSearch, sunbeam.CommandModeFilter, sunbeam.CommandModeDetail:
+		runner := NewRunner(alias, extension, input)
+
+		return PushPageCmd(runner)
+	case sunbeam.CommandModeSilent:
+		return func() tea.Msg {
+			_, err := extension.Output(input)
+
+			if err != nil {
+				return PushPageMsg{NewErrorPage(err)}
+			}
+
+			if msg.Run.Reload {
+				return ReloadMsg{}
 			}
 
-			return c, c.Reload()
+			if msg.Run.Exit {
+				return ExitMsg{}
+			}
+
+			return nil
+		}
+	case sunbeam.CommandModeTTY:
+		cmd, err := extension.Cmd(input)
+		if err != nil {
+			c.embed = NewErrorPage(err)
+			c.embed.SetSize(c.width, c.height)
+			return c.embed.Init()
 		}
 
-	case error:
-		c.embed = NewErrorPage(msg)
-		c.embed.SetSize(c.width, c.height)
-		return c, c.embed.Init()
-	}
+		return tea.ExecProcess(cmd, func(err error) tea.Msg {
+			if err != nil {
+				return PushPageMsg{NewErrorPage(extension.LogFailure(input, err))}
+			}
 
-	if c.form != nil {
-		form, cmd := c.form.Update(msg)
-		c.form = form.(*Form)
-		return c, cmd
+			if msg.Run.Reload {
+				c.embed.Focus()
+				return ReloadMsg{}
+			}
+
+			if msg.Run.Exit {
+				return ExitMsg{}
+			}
+
+			termenv.DefaultOutput().SetWindowTitle(fmt.Sprintf("%s - %s", c.command.Title, c.extension.Manifest.Title))
+			return c.embed.Focus()
+		})
 	}
 
-	var cmd tea.Cmd
-	c.embed, cmd = c.embed.Update(msg)
-	return c, cmd
+	return nil
 }
 
 func (c *Runner) View() string {
@@ -342,20 +484,11 @@ func (c *Runner) Reload() tea.Cmd {
 		c.cancel = cancel
 		defer cancel()
 
-		cmd, err := c.extension.CmdContext(ctx, c.input)
-		if err != nil {
-			return err
-		}
-
-		output, err := cmd.Output()
+		output, err := c.extension.OutputContext(ctx, c.input)
 		if err != nil {
 			if errors.Is(ctx.Err(), context.Canceled) {
 				return nil
 			}
-			var exitErr *exec.ExitError
-			if errors.As(err, &exitErr) {
-				return fmt.Errorf("command failed: %s", stripansi.Strip(string(exitErr.Stderr)))
-			}
 
 			return err
 		}
@@ -389,41 +522,123 @@ func (c *Runner) Reload() tea.Cmd {
 				return err
 			}
 
-			var page *List
-			if embed, ok := c.embed.(*List); ok {
-				page = embed
-				page.SetItems(list.Items...)
-				page.SetIsLoading(false)
-				page.SetEmptyText(list.EmptyText)
-				page.SetActions(list.Actions...)
-				page.SetShowDetail(list.ShowDetail)
-				page.SetAutoRefreshSeconds(list.AutoRefreshSeconds)
-
-				if c.command.Mode == sunbeam.CommandModeSearch {
-					page.OnQueryChange = func(query string) tea.Cmd {
-						c.input.Query = query
-						return c.Reload()
-					}
-					page.ResetSelection()
+			// the history is assigned by Update, as the items are ranked with it while the list is displayed
+			msg := listLoadedMsg{list: list}
+			if c.command.RememberSelection || list.RememberSelection {
+				store, err := history.Load(history.Path)
+				if err != nil {
+					return err
 				}
 
-				return nil
-			}
-
-			page = NewList(list.Items...)
-			page.SetEmptyText(list.EmptyText)
-			page.SetActions(list.Actions...)
-			page.SetShowDetail(list.ShowDetail)
-			if c.command.Mode == sunbeam.CommandModeSearch {
-				page.OnQueryChange = func(query string) tea.Cmd {
-					c.input.Query = query
-					return c.Reload()
-				}
+				msg.history = store
 			}
 
-			return page
+			return msg
 		default:
 			return fmt.Errorf("invalid view type")
 		}
 	})
 }
+
+func (c *Runner) setList(msg listLoadedMsg) tea.Cmd {
+	list := msg.list
+	c.rememberSelection = msg.history != nil
+	if c.rememberSelection {
+		c.history = msg.history
+
+		// the items of search commands are already sorted by the extension
+		if c.command.Mode == sunbeam.CommandModeFilter {
+			sortByRank(list.Items, func(item sunbeam.ListItem) float64 {
+				return c.history.Rank(c.historyKey(ListItem(item)), "")
+			})
+		}
+	}
+
+	if page, ok := c.embed.(*List); ok {
+		page.SetFilterBy(list.FilterBy)
+		page.SetItems(list.Items...)
+		page.SetEmptyText(list.EmptyText)
+		page.SetActions(list.Actions...)
+		page.SetShowDetail(list.ShowDetail)
+		page.SetAutoRefreshSeconds(list.AutoRefreshSeconds)
+		page.filter.Rank = c.rank()
+
+		if c.command.Mode == sunbeam.CommandModeSearch {
+			page.OnQueryChange = func(query string) tea.Cmd {
+				c.input.Query = query
+				return c.Reload()
+			}
+			page.ResetSelection()
+		}
+
+		return page.SetIsLoading(false)
+	}
+
+	page := NewList(list.Items...)
+	page.SetFilterBy(list.FilterBy)
+	page.filter.Rank = c.rank()
+	page.SetEmptyText(list.EmptyText)
+	page.SetActions(list.Actions...)
+	page.SetShowDetail(list.ShowDetail)
+	if c.command.Mode == sunbeam.CommandModeSearch {
+		page.OnQueryChange = func(query string) tea.Cmd {
+			c.input.Query = query
+			return c.Reload()
+		}
+	}
+
+	c.embed = page
+	c.embed.SetSize(c.width, c.height)
+	return c.embed.Init()
+}
+
+func (c *Runner) historyKey(item FilterItem) string {
+	return fmt.Sprintf("%s - %s - %s", c.alias, c.command.Name, item.ID())
+}
+
+func (c *Runner) rank() func(FilterItem, string) float64 {
+	if !c.rememberSelection {
+		return nil
+	}
+
+	return func(item FilterItem, query string) float64 {
+		return c.history.Rank(c.historyKey(item), query)
+	}
+}
+
+// recordSelection records the selected item when the action is one of its own, the returned command writes it to disk.
+func (c *Runner) recordSelection(action sunbeam.Action) tea.Cmd {
+	// actions submitted by a form were already recorded when the form was opened
+	if !c.rememberSelection || c.form != nil {
+		return nil
+	}
+
+	list, ok := c.embed.(*List)
+	if !ok {
+		return nil
+	}
+
+	selection, ok := list.Selection()
+	if !ok || !slices.ContainsFunc(selection.Actions, func(itemAction sunbeam.Action) bool {
+		return reflect.DeepEqual(itemAction, action)
+	}) {
+		return nil
+	}
+
+	key := c.historyKey(ListItem(selection))
+	query := list.Query()
+	c.history.Record(key, query)
+
+	return func() tea.Msg {
+		// the history of the runner keeps ranking the items meanwhile, the selection is saved from a store of its own
+		store := history.New(history.Path)
+		store.Record(key, query)
+
+		// the history only improves the ranking of the items, failing to save it must not prevent the action from running
+		if err := store.Save(); err != nil {
+			return ShowNotificationMsg{fmt.Sprintf("Failed to save history: %s", err)}
+		}
+
+		return nil
+	}
+}
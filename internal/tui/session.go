@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"github.com/pomdtr/sunbeam/internal/config"
+	"github.com/pomdtr/sunbeam/internal/extensions"
+	"github.com/pomdtr/sunbeam/internal/session"
+	"github.com/pomdtr/sunbeam/pkg/sunbeam"
+)
+
+// RestorablePage is implemented by the pages saved in the session.
+type RestorablePage interface {
+	SessionPage() (session.Page, bool)
+}
+
+func (c *Runner) SessionPage() (session.Page, bool) {
+	page := session.Page{
+		Extension: c.alias,
+		Command:   c.input.Command,
+		Params:    c.input.Params,
+	}
+
+	if list, ok := c.embed.(*List); ok {
+		page.Query = list.Query()
+	}
+
+	return page, c.alias != ""
+}
+
+// NewSession returns the session of the pages stacked on the root list. Pages that don't start
+// from the root list or from a command, like the forms used to configure an extension, have no session.
+func NewSession(pages []Page) (session.Session, bool) {
+	var s session.Session
+	for i, page := range pages {
+		restorable, ok := page.(RestorablePage)
+		if !ok {
+			if _, isRoot := page.(*RootList); i == 0 && isRoot {
+				continue
+			}
+
+			if i == 0 {
+				return session.Session{}, false
+			}
+			break
+		}
+
+		sessionPage, ok := restorable.SessionPage()
+		if !ok {
+			break
+		}
+		s.Pages = append(s.Pages, sessionPage)
+	}
+
+	return s, true
+}
+
+// RestoreSession rebuilds the pages of a session. The restoration stops at the first page
+// that can't be run anymore, for example when its extension was removed or needs a preference.
+func RestoreSession(cfg config.Config, s session.Session) []Page {
+	var pages []Page
+	for _, page := range s.Pages {
+		extensionConfig, ok := cfg.Extensions[page.Extension]
+		if !ok {
+			break
+		}
+
+		extension, err := extensions.LoadExtension(extensionConfig)
+		if err != nil {
+			break
+		}
+
+		command, ok := extension.Command(page.Command)
+		if !ok {
+			break
+		}
+
+		if command.Mode != sunbeam.CommandModeSearch && command.Mode != sunbeam.CommandModeFilter && command.Mode != sunbeam.CommandModeDetail {
+			break
+		}
+
+		preferences, err := LoadPreferences(page.Extension, extension)
+		if err != nil {
+			break
+		}
+
+		if hasRequiredInputs(FindMissingPreferences(extension.Manifest.Preferences, preferences)) {
+			break
+		}
+
+		params := make(map[string]any)
+		for k, v := range page.Params {
+			params[k] = v
+		}
+
+		if hasRequiredInputs(FindMissingInputs(command.Params, params)) {
+			break
+		}
+
+		pages = append(pages, NewRunner(page.Extension, extension, sunbeam.Payload{
+			Command:     command.Name,
+			Preferences: preferences,
+			Params:      params,
+			Query:       page.Query,
+		}))
+	}
+
+	return pages
+}
+
+func hasRequiredInputs(inputs []sunbeam.Input) bool {
+	for _, input := range inputs {
+		if !input.Optional {
+			return true
+		}
+	}
+
+	return false
+}
@@ -14,7 +14,7 @@ import (
 
 func separator(n int) string {
 	separator := strings.Repeat("─", n)
-	return lipgloss.NewStyle().Bold(true).Render(separator)
+	return theme.SeparatorStyle().Render(separator)
 }
 
 type StatusBar struct {
@@ -61,30 +61,41 @@ func (c *StatusBar) FilterActions(query string) {
 		return
 	}
 
-	c.filtered = make([]sunbeam.Action, 0)
+	type result struct {
+		action sunbeam.Action
+		score  int
+	}
+
+	pattern := fzf.ParsePattern(query)
+	results := make([]result, 0)
 	for i := 0; i < len(c.actions); i++ {
-		if fzf.Score(c.actions[i].Title, query) > 0 {
-			c.filtered = append(c.filtered, c.actions[i])
+		if ok, score, _ := pattern.Match(c.actions[i].Title); ok {
+			results = append(results, result{action: c.actions[i], score: score})
 		}
 	}
 
-	sort.SliceStable(c.filtered, func(i, j int) bool {
-		return fzf.Score(c.filtered[i].Title, query) > fzf.Score(c.filtered[j].Title, query)
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
 	})
 
+	c.filtered = make([]sunbeam.Action, len(results))
+	for i, result := range results {
+		c.filtered[i] = result.action
+	}
+
 	c.cursor = 0
 }
 
 func (p StatusBar) Update(msg tea.Msg) (StatusBar, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "tab", "right":
+		switch {
+		case keymap.Matches(msg, KeyNextAction):
 			if !p.expanded {
 				break
 			}
 
-			if msg.String() == "tab" && len(p.filtered) == 0 {
+			if len(p.filtered) == 0 {
 				return p, nil
 			}
 
@@ -95,7 +106,7 @@ func (p StatusBar) Update(msg tea.Msg) (StatusBar, tea.Cmd) {
 			}
 
 			return p, nil
-		case "shift+tab", "left":
+		case keymap.Matches(msg, KeyPreviousAction):
 			if !p.expanded {
 				break
 			}
@@ -105,7 +116,7 @@ func (p StatusBar) Update(msg tea.Msg) (StatusBar, tea.Cmd) {
 			} else {
 				p.cursor = len(p.filtered) - 1
 			}
-		case "enter":
+		case keymap.Matches(msg, KeyPrimaryAction):
 			if len(p.filtered) == 0 {
 				return p, nil
 			}
@@ -114,7 +125,7 @@ func (p StatusBar) Update(msg tea.Msg) (StatusBar, tea.Cmd) {
 			return p, func() tea.Msg {
 				return action
 			}
-		case "alt+enter":
+		case keymap.Matches(msg, KeySecondaryAction):
 			if p.cursor != 0 || len(p.actions) < 2 {
 				break
 			}
@@ -122,7 +133,7 @@ func (p StatusBar) Update(msg tea.Msg) (StatusBar, tea.Cmd) {
 			return p, func() tea.Msg {
 				return p.actions[1]
 			}
-		case "ctrl+d":
+		case keymap.Matches(msg, KeyClose):
 			if p.expanded {
 				break
 			}
@@ -130,7 +141,7 @@ func (p StatusBar) Update(msg tea.Msg) (StatusBar, tea.Cmd) {
 			return p, PopPageCmd
 		default:
 			for _, action := range p.actions {
-				if fmt.Sprintf("alt+%s", action.Key) == msg.String() {
+				if key := keymap.ActionKey(action.Key); key != "" && key == msg.String() {
 					return p, func() tea.Msg {
 						return action
 					}
@@ -184,21 +195,51 @@ func ActionTitle(action sunbeam.Action) string {
 	}
 }
 
+// statusBarHit is the span of a clickable part of the status bar. The index is the index
+// of the action in the filtered actions, or -1 for the hint showing the actions.
+type statusBarHit struct {
+	start, end int
+	index      int
+}
+
 func (c StatusBar) View() string {
-	var accessory string
 	if len(c.actions) == 0 {
 		return lipgloss.JoinVertical(lipgloss.Left, separator(c.Width), strings.Repeat(" ", c.Width))
 	}
+
+	statusbar, _ := c.render()
+	return lipgloss.JoinVertical(lipgloss.Left, separator(c.Width), statusbar)
+}
+
+// HitTest returns the clickable part of the status bar at the given column.
+func (c StatusBar) HitTest(x int) (int, bool) {
+	if len(c.actions) == 0 {
+		return 0, false
+	}
+
+	_, hits := c.render()
+	for _, hit := range hits {
+		if x >= hit.start && x < hit.end {
+			return hit.index, true
+		}
+	}
+
+	return 0, false
+}
+
+// render returns the line of the status bar, along with the position of its clickable parts.
+func (c StatusBar) render() (string, []statusBarHit) {
+	var hits []statusBarHit
 	if c.expanded {
 		accessories := make([]string, len(c.filtered))
 		for i, action := range c.filtered {
 			var subtitle string
 			if i == 0 {
-				subtitle = "enter"
+				subtitle = keymap.Key(KeyPrimaryAction)
 			} else if i == 1 {
-				subtitle = "alt+enter"
-			} else if action.Key != "" {
-				subtitle = fmt.Sprintf("alt+%s", action.Key)
+				subtitle = keymap.Key(KeySecondaryAction)
+			} else {
+				subtitle = keymap.ActionKey(action.Key)
 			}
 			accessories[i] = renderAction(ActionTitle(action), subtitle, i == c.cursor)
 		}
@@ -219,7 +260,17 @@ func (c StatusBar) View() string {
 			}
 		}
 
-		accessory = strings.Join(accessories[startIdx:endIdx], " · ")
+		offset := 3
+		if startIdx > 0 {
+			offset += lipgloss.Width("… · ")
+		}
+		for i := startIdx; i < endIdx; i++ {
+			width := lipgloss.Width(accessories[i])
+			hits = append(hits, statusBarHit{start: offset, end: offset + width, index: i})
+			offset += width + lipgloss.Width(" · ")
+		}
+
+		accessory := strings.Join(accessories[startIdx:endIdx], " · ")
 		if startIdx > 0 {
 			accessory = fmt.Sprintf("… · %s", accessory)
 		}
@@ -227,32 +278,33 @@ func (c StatusBar) View() string {
 			accessory = fmt.Sprintf("%s · …", accessory)
 		}
 
-	} else {
-		accessory = fmt.Sprintf("%s · Actions %s", renderAction(ActionTitle(c.filtered[0]), "enter", false), lipgloss.NewStyle().Faint(true).Render("tab"))
+		return fmt.Sprintf("   %s ", accessory), hits
 	}
 
-	var statusbar string
-	if c.expanded {
-		statusbar = fmt.Sprintf("   %s ", accessory)
-	} else {
+	primary := renderAction(ActionTitle(c.filtered[0]), keymap.Key(KeyPrimaryAction), false)
+	accessory := fmt.Sprintf("%s · Actions %s", primary, theme.FaintStyle().Render(keymap.Key(KeyShowActions)))
 
-		blanks := strings.Repeat(" ", max(c.Width-lipgloss.Width(accessory)-lipgloss.Width(c.notification)-4, 0))
-		statusbar = fmt.Sprintf("   %s%s%s ", lipgloss.NewStyle().Faint(true).Render(c.notification), blanks, accessory)
-	}
+	blanks := strings.Repeat(" ", max(c.Width-lipgloss.Width(accessory)-lipgloss.Width(c.notification)-4, 0))
+	statusbar := fmt.Sprintf("   %s%s%s ", theme.FaintStyle().Render(c.notification), blanks, accessory)
 
-	return lipgloss.JoinVertical(lipgloss.Left, separator(c.Width), statusbar)
+	offset := 3 + lipgloss.Width(c.notification) + len(blanks)
+	hits = append(hits, statusBarHit{start: offset, end: offset + lipgloss.Width(primary), index: 0})
+	offset += lipgloss.Width(primary) + lipgloss.Width(" · ")
+	hits = append(hits, statusBarHit{start: offset, end: lipgloss.Width(statusbar) - 1, index: -1})
+
+	return statusbar, hits
 }
 
 func renderAction(title string, subtitle string, selected bool) string {
 	var view string
 	if subtitle != "" {
-		view = fmt.Sprintf("%s %s", title, lipgloss.NewStyle().Faint(true).Render(subtitle))
+		view = fmt.Sprintf("%s %s", title, theme.FaintStyle().Render(subtitle))
 	} else {
 		view = title
 	}
 
 	if selected {
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true).Render(view)
+		return theme.AccentStyle().Bold(true).Render(view)
 	}
 
 	return view
@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pomdtr/sunbeam/internal/config"
+)
+
+type Theme struct {
+	Accent    lipgloss.TerminalColor
+	Faint     lipgloss.TerminalColor
+	Separator lipgloss.TerminalColor
+	Markdown  ansi.StyleConfig
+}
+
+var themes = map[string]Theme{
+	"dark": {
+		Accent:    lipgloss.Color("13"),
+		Faint:     lipgloss.NoColor{},
+		Separator: lipgloss.NoColor{},
+		Markdown:  styles.PinkStyleConfig,
+	},
+	"light": {
+		Accent:    lipgloss.Color("5"),
+		Faint:     lipgloss.NoColor{},
+		Separator: lipgloss.NoColor{},
+		Markdown:  styles.LightStyleConfig,
+	},
+	"no-color": {
+		Accent:    lipgloss.NoColor{},
+		Faint:     lipgloss.NoColor{},
+		Separator: lipgloss.NoColor{},
+		Markdown:  styles.NoTTYStyleConfig,
+	},
+}
+
+// theme is used by every page, it is replaced by the one of the config when sunbeam starts.
+var theme = themes["dark"]
+
+func SetTheme(t Theme) {
+	theme = t
+}
+
+// NewTheme builds the theme of the config, on top of a built-in theme.
+// The no-color theme is always used when the NO_COLOR environment variable is set.
+func NewTheme(cfg *config.Theme) (Theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return themes["no-color"], nil
+	}
+
+	if cfg == nil {
+		return themes["dark"], nil
+	}
+
+	name := cfg.Name
+	if name == "" {
+		name = "dark"
+	}
+
+	t, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme: %s", name)
+	}
+
+	if cfg.Accent != "" {
+		t.Accent = lipgloss.Color(cfg.Accent)
+	}
+
+	if cfg.Faint != "" {
+		t.Faint = lipgloss.Color(cfg.Faint)
+	}
+
+	if cfg.Separator != "" {
+		t.Separator = lipgloss.Color(cfg.Separator)
+	}
+
+	if cfg.IsMarkdownFile() {
+		content, err := os.ReadFile(cfg.Markdown)
+		if err != nil {
+			return Theme{}, fmt.Errorf("failed to read markdown style: %w", err)
+		}
+
+		var style ansi.StyleConfig
+		if err := json.Unmarshal(content, &style); err != nil {
+			return Theme{}, fmt.Errorf("failed to parse markdown style: %w", err)
+		}
+		t.Markdown = style
+	} else if cfg.Markdown != "" {
+		style, ok := styles.DefaultStyles[cfg.Markdown]
+		if !ok {
+			return Theme{}, fmt.Errorf("unknown markdown style: %s", cfg.Markdown)
+		}
+		t.Markdown = *style
+	}
+
+	return t, nil
+}
+
+func (t Theme) AccentStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(t.Accent)
+}
+
+// FaintStyle is used for the secondary text, it falls back to the faint attribute when no color is set.
+func (t Theme) FaintStyle() lipgloss.Style {
+	if _, ok := t.Faint.(lipgloss.NoColor); ok {
+		return lipgloss.NewStyle().Faint(true)
+	}
+
+	return lipgloss.NewStyle().Foreground(t.Faint)
+}
+
+func (t Theme) SeparatorStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(t.Separator).Bold(true)
+}
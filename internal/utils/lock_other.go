@@ -0,0 +1,7 @@
+//go:build !unix
+
+package utils
+
+func LockFile(name string) (func() error, error) {
+	return func() error { return nil }, nil
+}
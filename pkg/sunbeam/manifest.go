@@ -5,14 +5,18 @@ type Manifest struct {
 	Description string        `json:"description,omitempty"`
 	Preferences []Input       `json:"preferences,omitempty"`
 	Commands    []CommandSpec `json:"commands"`
+	Timeout     int           `json:"timeout,omitempty"`
 }
 
 type CommandSpec struct {
-	Name   string      `json:"name"`
-	Title  string      `json:"title"`
-	Hidden bool        `json:"hidden,omitempty"`
-	Params []Input     `json:"params,omitempty"`
-	Mode   CommandMode `json:"mode,omitempty"`
+	Name              string      `json:"name"`
+	Title             string      `json:"title"`
+	Description       string      `json:"description,omitempty"`
+	Hidden            bool        `json:"hidden,omitempty"`
+	Params            []Input     `json:"params,omitempty"`
+	Mode              CommandMode `json:"mode,omitempty"`
+	Timeout           int         `json:"timeout,omitempty"`
+	RememberSelection bool        `json:"rememberSelection,omitempty"`
 }
 
 type Platfom string
@@ -51,4 +55,5 @@ type Input struct {
 	Title    string    `json:"title"`
 	Optional bool      `json:"optional,omitempty"`
 	Default  any       `json:"default,omitempty"`
+	Secret   bool      `json:"secret,omitempty"`
 }
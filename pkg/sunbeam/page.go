@@ -6,6 +6,8 @@ type List struct {
 	ShowDetail         bool       `json:"showDetail,omitempty"`
 	AutoRefreshSeconds int        `json:"autoRefreshSeconds,omitempty"`
 	Actions            []Action   `json:"actions,omitempty"`
+	RememberSelection  bool       `json:"rememberSelection,omitempty"`
+	FilterBy           []string   `json:"filterBy,omitempty"`
 }
 
 type ListItem struct {
@@ -14,6 +16,7 @@ type ListItem struct {
 	Subtitle    string         `json:"subtitle,omitempty"`
 	Detail      ListItemDetail `json:"detail,omitempty"`
 	Accessories []string       `json:"accessories,omitempty"`
+	Keywords    []string       `json:"keywords,omitempty"`
 	Actions     []Action       `json:"actions,omitempty"`
 }
 
@@ -23,6 +23,7 @@ export type EditAction = {
 
 export type RunAction = {
   type: "run";
+  extension?: string;
   command: string;
   params?: Record<string, Param>;
   reload?: boolean;
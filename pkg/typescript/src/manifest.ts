@@ -3,14 +3,18 @@ export type Manifest = {
   description: string;
   preferences?: readonly Input[];
   commands: readonly Command[];
+  timeout?: number;
 };
 
 export type Command = {
   name: string;
   hidden?: boolean;
   title: string;
+  description?: string;
   params?: readonly Input[];
   mode: "filter" | "search" | "detail" | "tty" | "silent";
+  timeout?: number;
+  rememberSelection?: boolean;
 };
 
 export type Input = {
@@ -18,6 +22,7 @@ export type Input = {
   title: string;
   type: "string" | "number" | "boolean";
   optional?: boolean;
+  secret?: boolean;
 };
 
 type InputMap = {
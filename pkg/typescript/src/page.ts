@@ -6,6 +6,8 @@ export type List = {
   showDetail?: boolean;
   autoRefreshSeconds?: number;
   emptyText?: string;
+  rememberSelection?: boolean;
+  filterBy?: ("subtitle" | "accessories")[];
 };
 
 export type Detail = {
@@ -18,6 +20,7 @@ export type ListItem = {
   title: string;
   subtitle?: string;
   accessories?: string[];
+  keywords?: string[];
   detail?: { text: string; } | { markdown: string; }
   actions?: Action[];
 };